@@ -8,15 +8,23 @@ import (
 	"os/signal"
 	"syscall"
 
+	"cayman/internal/data/logfile"
 	"cayman/internal/modules"
+	"cayman/internal/modules/logs"
 )
 
 func main() {
 	var (
-		addr = flag.String("addr", "0.0.0.0", "listen address")
-		port = flag.String("port", "8080", "listen port")
+		addr       = flag.String("addr", "0.0.0.0", "listen address")
+		port       = flag.String("port", "8080", "listen port")
+		adminToken = flag.String("admin-token", "", "bearer token granting the admin role (default $CAYMAN_ADMIN_TOKEN)")
+		logFiles   = flag.String("log-files", os.Getenv("CAYMAN_LOG_FILES"), "JSON file listing plain-text log files to tail (default $CAYMAN_LOG_FILES)")
 	)
 	flag.Parse()
+	// read after parsing so usage messages never print the token
+	if *adminToken == "" {
+		*adminToken = os.Getenv("CAYMAN_ADMIN_TOKEN")
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -25,7 +33,16 @@ func main() {
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
-	engine := modules.NewEngine(logger, *addr, *port)
+	if *logFiles != "" {
+		sources, err := logfile.LoadSources(*logFiles)
+		if err != nil {
+			logger.Error("failed to load log files", "error", err)
+			os.Exit(1)
+		}
+		logs.SetFileSources(sources)
+	}
+
+	engine := modules.NewEngine(logger, *addr, *port, *adminToken)
 	if err := engine.Start(ctx); err != nil {
 		logger.Error("failed to start engine", "error", err)
 	}
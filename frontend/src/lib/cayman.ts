@@ -2,6 +2,7 @@
 import type {HostInfo} from "./sysinfo.ts"
 import type {HostMemoryInfo} from "./sysinfo.ts"
 import type {Summary as ContainerSummary}  from "./dockercontainer.ts"
+import type {State as ContainerState}  from "./dockercontainer.ts"
 import type {Summary as ImageSummary}  from "./dockerimage.ts"
 import type {InstanceFull} from "./incus.ts"
 import type {Image} from "./incus.ts"
@@ -11,6 +12,23 @@ import type {Image} from "./incus.ts"
 
 export type Module = any;
 
+//////////
+// source: types_audit.go
+
+/**
+ * AuditEntry records a privileged action performed through the API.
+ */
+export interface AuditEntry {
+    time: string;
+    role: string;
+    remote_addr: string;
+    action: string; // e.g. process.signal
+    target: string; // e.g. the PID or unit name
+    params?: { [key: string]: string};
+    success: boolean;
+    error?: string;
+}
+
 //////////
 // source: types_dashboard.go
 
@@ -31,6 +49,22 @@ export interface HostState {
     logical_cores: number /* int */; // Include logical cores
     host_info: HostInfo; // Include host info
     memory_info: HostMemoryInfo; // Include memory info
+    network: NetInterface[]; // Include network interfaces
+    disk_io: DiskIO[]; // Include block device activity
+    pressure: PressureInfo; // Include pressure stall information
+    sensors: SensorsInfo; // Include hardware sensors
+    memory: MemoryDetail; // Include memory breakdown
+}
+/**
+ * MetricsSample is one entry of the dashboard metrics history.
+ */
+export interface MetricsSample {
+    time: string;
+    cpu: number /* int */;
+    load: Load;
+    disk_io: DiskIO[];
+    sensors: Sensor[];
+    memory: MemoryDetail;
 }
 export interface UnitStatus {
     failed_count: number /* int */;
@@ -42,6 +76,22 @@ export interface Load {
     load15: number /* float64 */;
 }
 
+//////////
+// source: types_disk.go
+
+/**
+ * DiskIO is the I/O activity of a block device since the previous sample.
+ */
+export interface DiskIO {
+    name: string;
+    read_bytes_per_sec: number /* float64 */;
+    write_bytes_per_sec: number /* float64 */;
+    read_iops: number /* float64 */;
+    write_iops: number /* float64 */;
+    await_ms: number /* float64 */; // average time per completed request
+    utilization: number /* float64 */; // percent of time the device was busy
+}
+
 //////////
 // source: types_docker.go
 
@@ -49,6 +99,76 @@ export interface DockerInfo {
     containers: ContainerSummary[];
     images: ImageSummary[];
 }
+/**
+ * ContainerActionResult confirms the outcome of an action on a container.
+ */
+export interface ContainerActionResult {
+    id: string;
+    action: string;
+    state?: ContainerState; // nil once the container is removed
+    error?: string;
+}
+/**
+ * ContainerEvent reports a change to a container, from a daemon event or an
+ * action.
+ */
+export interface ContainerEvent {
+    id: string;
+    action: string; // the daemon's event action, e.g. start or die
+    /**
+     * Container is the new summary, nil once the container is removed.
+     */
+    container?: ContainerSummary;
+}
+/**
+ * ContainerLogLine is a line written by a container to its standard output
+ * or error.
+ */
+export interface ContainerLogLine {
+    stream: string; // stdout or stderr
+    /**
+     * Time is when the daemon received the line, only set when timestamps
+     * are requested.
+     */
+    time?: string;
+    message: string;
+}
+/**
+ * TerminalMessage is a control message of an exec terminal, sent as a
+ * WebSocket text message. Terminal output is sent as binary messages.
+ */
+export interface TerminalMessage {
+    type: string; // stdin or resize from the client, exit or error from the server
+    data?: string; // stdin: keystrokes to send, error: the error
+    cols?: number /* uint */; // resize: terminal width
+    rows?: number /* uint */; // resize: terminal height
+    /**
+     * ExitCode is the exit code of the command, for exit; -1 when it is
+     * unknown.
+     */
+    exit_code: number /* int */;
+}
+/**
+ * ContainerStats is a resource usage sample of a running container. Byte
+ * counters are totals since the container started; rates are per second
+ * since the previous sample.
+ */
+export interface ContainerStats {
+    id: string;
+    name: string;
+    time: string;
+    cpu_percent: number /* float64 */; // 100 is one full CPU
+    memory_usage: number /* uint64 */; // excluding inactive page cache, as docker stats
+    memory_limit: number /* uint64 */;
+    net_rx: number /* uint64 */;
+    net_tx: number /* uint64 */;
+    net_rx_rate: number /* float64 */;
+    net_tx_rate: number /* float64 */;
+    block_read: number /* uint64 */;
+    block_write: number /* uint64 */;
+    block_read_rate: number /* float64 */;
+    block_write_rate: number /* float64 */;
+}
 
 //////////
 // source: types_incus.go
@@ -57,3 +177,428 @@ export interface IncusInfo {
     instances: InstanceFull[];
     images: Image[];
 }
+
+//////////
+// source: types_logs.go
+
+/**
+ * LogEntry is a single log record, decoded from the journal's well known
+ * fields.
+ */
+export interface LogEntry {
+    /**
+     * Cursor identifies the entry in the journal and is used to page from it.
+     */
+    cursor: string;
+    time: string;
+    priority: number /* int */; // syslog level, 0 (emerg) to 7 (debug)
+    facility?: string; // syslog facility, e.g. kern or daemon
+    hostname: string;
+    identifier: string; // SYSLOG_IDENTIFIER, e.g. sshd
+    pid: number /* int32 */;
+    unit?: string; // system unit
+    user_unit?: string; // unit of the user manager
+    boot_id: string;
+    transport: string; // journal, syslog, stdout, kernel, audit, driver or file
+    container?: string; // set by the docker and podman journald drivers
+    message: string;
+    /**
+     * Source is the path of the file an entry was read from, for the file
+     * transport.
+     */
+    source?: string;
+}
+/**
+ * LogPage is one page of a log query, newest entry first.
+ */
+export interface LogPage {
+    entries: LogEntry[];
+    /**
+     * Next is the cursor to pass to fetch the following, older page. It is
+     * empty when there are no older entries.
+     */
+    next?: string;
+}
+/**
+ * LogExportHeader precedes the entries of a log export, identifying the host
+ * and the filters it was made with.
+ */
+export interface LogExportHeader {
+    exported_at: string;
+    query: string; // filter query params of the export
+    hostname: string;
+    fqdn: string;
+    host_info: HostInfo;
+}
+/**
+ * Boot is a boot recorded in the journal.
+ */
+export interface Boot {
+    /**
+     * Index is relative to the current boot: 0 is the current boot, -1 the
+     * one before, and so on.
+     */
+    index: number /* int */;
+    id: string;
+    first_entry: string;
+    last_entry: string;
+    /**
+     * Kernel is the kernel release, read from the boot's "Linux version"
+     * message. It is empty when the kernel messages were not kept.
+     */
+    kernel?: string;
+}
+
+//////////
+// source: types_memory.go
+
+/**
+ * MemoryDetail is a breakdown of memory usage from /proc/meminfo and
+ * /proc/vmstat. All sizes are in bytes.
+ */
+export interface MemoryDetail {
+    total: number /* uint64 */;
+    free: number /* uint64 */;
+    available: number /* uint64 */;
+    buffers: number /* uint64 */;
+    cached: number /* uint64 */; // page cache, excluding swap cache
+    anon: number /* uint64 */;
+    shmem: number /* uint64 */;
+    slab: number /* uint64 */;
+    sreclaimable: number /* uint64 */;
+    sunreclaim: number /* uint64 */;
+    mapped: number /* uint64 */;
+    page_tables: number /* uint64 */;
+    kernel_stack: number /* uint64 */;
+    dirty: number /* uint64 */;
+    writeback: number /* uint64 */;
+    swap_total: number /* uint64 */;
+    swap_free: number /* uint64 */;
+    swap_cached: number /* uint64 */;
+    swap_devices: SwapDevice[];
+    huge_pages: HugePages;
+    zswap: ZswapStats;
+    zram: ZramDevice[];
+    /**
+     * cumulative counters since boot
+     */
+    swap_ins: number /* uint64 */; // pages
+    swap_outs: number /* uint64 */; // pages
+    major_faults: number /* uint64 */;
+    oom_kills: number /* uint64 */;
+}
+/**
+ * SwapDevice is an entry of /proc/swaps.
+ */
+export interface SwapDevice {
+    name: string;
+    type: string; // partition or file
+    size: number /* uint64 */;
+    used: number /* uint64 */;
+    priority: number /* int */;
+}
+/**
+ * HugePages describes the explicit (hugetlbfs) huge page pool and
+ * transparent huge page usage.
+ */
+export interface HugePages {
+    page_size: number /* uint64 */;
+    total: number /* uint64 */; // pages
+    free: number /* uint64 */; // pages
+    reserved: number /* uint64 */;
+    surplus: number /* uint64 */;
+    anon_huge_pages: number /* uint64 */; // transparent, bytes
+}
+/**
+ * ZswapStats is the compressed swap cache.
+ */
+export interface ZswapStats {
+    enabled: boolean;
+    pool: number /* uint64 */; // compressed size in memory
+    stored: number /* uint64 */; // uncompressed size of the stored pages
+}
+/**
+ * ZramDevice is a compressed RAM block device, typically used as swap.
+ */
+export interface ZramDevice {
+    name: string;
+    algorithm: string;
+    disk_size: number /* uint64 */;
+    orig_data_size: number /* uint64 */;
+    compr_data_size: number /* uint64 */;
+    mem_used_total: number /* uint64 */;
+}
+
+//////////
+// source: types_network.go
+
+/**
+ * NetInterface describes a network interface and its throughput since the
+ * previous sample.
+ */
+export interface NetInterface {
+    name: string;
+    hardware_addr: string;
+    addresses: string[]; // CIDR notation
+    oper_state: string; // up, down, unknown, ...
+    speed: number /* int */; // Mbit/s, -1 if unknown
+    mtu: number /* int */;
+    rx_bytes_per_sec: number /* float64 */;
+    tx_bytes_per_sec: number /* float64 */;
+    rx_bytes: number /* uint64 */;
+    tx_bytes: number /* uint64 */;
+    rx_packets: number /* uint64 */;
+    tx_packets: number /* uint64 */;
+    rx_errors: number /* uint64 */;
+    tx_errors: number /* uint64 */;
+    rx_dropped: number /* uint64 */;
+    tx_dropped: number /* uint64 */;
+}
+
+//////////
+// source: types_pressure.go
+
+/**
+ * PressureStat is one line of a Linux PSI pressure file. Averages are the
+ * percentage of time stalled over the window, Total is cumulative stall time
+ * in microseconds.
+ */
+export interface PressureStat {
+    avg10: number /* float64 */;
+    avg60: number /* float64 */;
+    avg300: number /* float64 */;
+    total: number /* uint64 */;
+}
+/**
+ * Pressure holds the "some" (at least one task stalled) and "full" (all
+ * non-idle tasks stalled) lines for a resource.
+ */
+export interface Pressure {
+    some: PressureStat;
+    full: PressureStat;
+}
+/**
+ * PressureInfo is the Pressure Stall Information for cpu, memory and io.
+ */
+export interface PressureInfo {
+    cpu: Pressure;
+    memory: Pressure;
+    io: Pressure;
+}
+/**
+ * CgroupPressure is the Pressure Stall Information of a single cgroup, such
+ * as a systemd service or a container.
+ */
+export interface CgroupPressure {
+    cgroup: string;
+    pressure: PressureInfo;
+}
+
+//////////
+// source: types_processes.go
+
+/**
+ * ProcessInfo is a row of the process list.
+ */
+export interface ProcessInfo {
+    pid: number /* int32 */;
+    ppid: number /* int32 */;
+    name: string;
+    user: string;
+    command: string; // full command line
+    cpu_percent: number /* float64 */; // percent of one CPU since the previous sample
+    rss: number /* uint64 */; // bytes
+    threads: number /* int32 */;
+    state: string; // running, sleep, stop, idle, zombie, wait, lock
+    start_time: string;
+    cgroup: string;
+    unit: string; // systemd unit owning the cgroup, if any
+}
+/**
+ * ProcessLimit is one line of /proc/<pid>/limits.
+ */
+export interface ProcessLimit {
+    resource: string;
+    soft: string;
+    hard: string;
+    units: string;
+}
+/**
+ * ProcessDetail is the expanded view of a single process. Environment is
+ * only populated for admin requests.
+ */
+export interface ProcessDetail {
+    process: ProcessInfo;
+    executable: string;
+    cwd: string;
+    open_files: string[];
+    environment?: string[];
+    limits: ProcessLimit[];
+    children: ProcessInfo[];
+}
+/**
+ * ProcessScheduling is the CPU and I/O scheduling configuration of a process.
+ */
+export interface ProcessScheduling {
+    nice: number /* int */;
+    io_class: string; // none, realtime, best-effort, idle
+    io_level: number /* int */; // 0 (highest) to 7
+    affinity: number /* int */[]; // CPUs the process may run on
+}
+/**
+ * ProcessActionResult confirms the outcome of a signal or scheduling change.
+ * Scheduling is omitted once the process has exited.
+ */
+export interface ProcessActionResult {
+    pid: number /* int32 */;
+    action: string;
+    running: boolean;
+    scheduling?: ProcessScheduling;
+}
+
+//////////
+// source: types_sensors.go
+
+/**
+ * SensorKind is the quantity a Sensor measures.
+ */
+export type SensorKind = string;
+export const SensorKindTemperature: SensorKind = "temperature"; // degrees Celsius
+export const SensorKindFan: SensorKind = "fan"; // RPM
+export const SensorKindPower: SensorKind = "power"; // watts
+/**
+ * Sensor is a single hardware monitoring reading. High, Critical and Low are
+ * zero when the driver does not report a threshold.
+ */
+export interface Sensor {
+    /**
+     * ID identifies the sensor by its device and channel; chip and label
+     * repeat across identical devices, e.g. Composite on every NVMe drive.
+     */
+    id: string;
+    chip: string;
+    label: string;
+    kind: SensorKind;
+    value: number /* float64 */;
+    high: number /* float64 */;
+    critical: number /* float64 */; // temperature and power only
+    low: number /* float64 */; // fan minimum speed
+    alarm: boolean; // set by the driver, e.g. a fan below its minimum
+}
+/**
+ * PowerSupply is a battery or AC adapter.
+ */
+export interface PowerSupply {
+    name: string;
+    type: string; // Battery, Mains, USB, ...
+    status: string; // Charging, Discharging, Full, ... (batteries only)
+    online: boolean; // adapter connected (mains only)
+    capacity: number /* int */; // percent, -1 if unknown
+}
+/**
+ * SensorsInfo groups all hardware sensor readings of the host.
+ */
+export interface SensorsInfo {
+    sensors: Sensor[];
+    power_supplies: PowerSupply[];
+}
+
+//////////
+// source: types_systemd.go
+
+/**
+ * UnitScope is the systemd manager a unit belongs to.
+ */
+export type UnitScope = string;
+export const UnitScopeSystem: UnitScope = "system"; // the system manager, PID 1
+export const UnitScopeUser: UnitScope = "user"; // the user manager of the user cayman runs as
+/**
+ * Unit is a systemd unit as listed by the systemd module.
+ */
+export interface Unit {
+    name: string;
+    scope: UnitScope;
+    description: string;
+    type: string; // service, socket, timer, ...
+    load_state: string; // loaded, not-found, masked, ...
+    active_state: string; // active, inactive, failed, ...
+    sub_state: string; // running, exited, dead, ...
+    enabled_state: string; // enabled, disabled, static, masked, ...
+    main_pid: number /* uint32 */; // services only, 0 when not running
+}
+/**
+ * UnitFileChange is a symlink created or removed by enabling, disabling or
+ * masking a unit.
+ */
+export interface UnitFileChange {
+    type: string; // symlink or unlink
+    filename: string;
+    destination: string;
+}
+/**
+ * UnitActionResult confirms the outcome of an action on a unit.
+ */
+export interface UnitActionResult {
+    unit: string;
+    action: string;
+    job_result?: string; // done, failed, canceled, timeout, dependency, skipped
+    changes?: UnitFileChange[];
+    state: Unit;
+    error?: string;
+}
+/**
+ * UnitFile is the content of a unit file or one of its drop-ins.
+ */
+export interface UnitFile {
+    path: string;
+    content: string;
+}
+/**
+ * UnitDetail is the expanded view of a single unit.
+ */
+export interface UnitDetail {
+    unit: Unit;
+    /**
+     * Properties holds every D-Bus property of the unit, including the type
+     * specific ones such as ExecMainStatus or MemoryCurrent for services.
+     * Timestamps are microseconds since the epoch.
+     */
+    properties: { [key: string]: any};
+    /**
+     * Dependencies maps dependency properties such as Requires, After or
+     * the reverse WantedBy to the units listed in them.
+     */
+    dependencies: { [key: string]: string[]};
+    /**
+     * Files is the unit file followed by its drop-ins in the order applied.
+     */
+    files: UnitFile[];
+}
+/**
+ * Timer is a systemd timer unit and the last run of the unit it activates.
+ * Times are omitted when systemd reports none.
+ */
+export interface Timer {
+    name: string;
+    scope: UnitScope;
+    description: string;
+    active_state: string;
+    unit: string; // unit activated by the timer, usually a service
+    /**
+     * NextElapseRealtime is the next calendar (OnCalendar=) trigger.
+     */
+    next_elapse_realtime?: string;
+    /**
+     * NextElapseMonotonic is the next relative (OnBootSec=, OnUnitActiveSec=,
+     * ...) trigger converted to wall clock time.
+     */
+    next_elapse_monotonic?: string;
+    last_trigger?: string;
+    /**
+     * UnitResult is the result of the last run of Unit: success, exit-code,
+     * signal, timeout, ...
+     */
+    unit_result: string;
+    unit_active_state: string;
+    unit_exit_status: number /* int32 */;
+}
@@ -4,14 +4,17 @@ go 1.24.5
 
 require (
 	github.com/bketelsen/inclient v0.4.0
+	github.com/containerd/errdefs v1.0.0
 	github.com/containers/podman/v5 v5.5.2
 	github.com/coreos/go-systemd/v22 v22.5.1-0.20231103132048-7d375ecc2b09
 	github.com/docker/docker v28.3.3+incompatible
 	github.com/elastic/go-sysinfo v1.15.3
+	github.com/gorilla/websocket v1.5.3
 	github.com/labstack/echo/v4 v4.13.4
 	github.com/lxc/incus/v6 v6.15.0
 	github.com/shirou/gopsutil/v4 v4.25.7
 	github.com/tmaxmax/go-sse v0.11.0
+	golang.org/x/sys v0.35.0
 )
 
 require (
@@ -29,7 +32,6 @@ require (
 	github.com/bep/golibsass v1.2.0 // indirect
 	github.com/blang/semver/v4 v4.0.0 // indirect
 	github.com/caarlos0/svu v1.12.0 // indirect
-	github.com/containerd/errdefs v1.0.0 // indirect
 	github.com/containerd/errdefs/pkg v0.3.0 // indirect
 	github.com/containers/common v0.64.1 // indirect
 	github.com/containers/storage v1.59.1 // indirect
@@ -56,7 +58,6 @@ require (
 	github.com/google/go-cmp v0.7.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/gorilla/securecookie v1.1.2 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/gzuidhof/tygo v0.2.19 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
@@ -119,7 +120,6 @@ require (
 	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
-	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/term v0.34.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	golang.org/x/time v0.12.0 // indirect
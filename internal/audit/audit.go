@@ -0,0 +1,64 @@
+// Package audit keeps a trail of privileged actions. Entries are written to
+// the log, so they survive restarts wherever the log is persisted, and the
+// most recent ones are kept in memory for the API.
+package audit
+
+import (
+	"log/slog"
+	"net"
+	"time"
+
+	"cayman"
+	"cayman/internal/auth"
+	"cayman/internal/ringbuffer"
+
+	"github.com/labstack/echo/v4"
+)
+
+// retained is the number of entries kept in memory.
+const retained = 1000
+
+var trail = ringbuffer.New[cayman.AuditEntry](retained)
+
+// Record adds an entry for an action performed by the request in c. A nil
+// err marks the action as successful.
+func Record(c echo.Context, action, target string, params map[string]string, err error) {
+	entry := cayman.AuditEntry{
+		Time:       time.Now(),
+		Role:       string(auth.RoleOf(c)),
+		RemoteAddr: remoteAddr(c),
+		Action:     action,
+		Target:     target,
+		Params:     params,
+		Success:    err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	trail.Add(entry)
+	slog.Info("audit",
+		"action", entry.Action,
+		"target", entry.Target,
+		"params", entry.Params,
+		"role", entry.Role,
+		"remote_addr", entry.RemoteAddr,
+		"success", entry.Success,
+		"error", entry.Error,
+	)
+}
+
+// remoteAddr returns the address of the peer of the request. Unlike
+// RealIP, headers such as X-Forwarded-For are ignored since any client can
+// set them.
+func remoteAddr(c echo.Context) string {
+	addr := c.Request().RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// Entries returns the retained entries, oldest first.
+func Entries() []cayman.AuditEntry {
+	return trail.Get()
+}
@@ -0,0 +1,88 @@
+// Package auth assigns roles to API requests. cayman has no user accounts;
+// a request is an admin when it presents the configured admin token as a
+// bearer token and a viewer otherwise.
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// Role is the level of access granted to a request.
+type Role string
+
+const (
+	// RoleViewer may read state but not change it.
+	RoleViewer Role = "viewer"
+	// RoleAdmin may additionally perform actions and see sensitive data.
+	RoleAdmin Role = "admin"
+)
+
+const roleKey = "cayman.role"
+
+// Middleware stores the role of each request in the echo context. When
+// adminToken is empty every request is a viewer.
+func Middleware(adminToken string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role := RoleViewer
+			token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok {
+				token, ok = websocketToken(c.Request())
+			}
+			if ok && adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+				role = RoleAdmin
+			}
+			c.Set(roleKey, role)
+			return next(c)
+		}
+	}
+}
+
+// WebSocketTokenPrefix marks the WebSocket subprotocol carrying the admin
+// token. Browsers cannot set headers on WebSocket requests, so clients offer
+// "cayman.bearer.<token>" alongside the protocol they speak.
+const WebSocketTokenPrefix = "cayman.bearer."
+
+// websocketToken returns the token offered as a WebSocket subprotocol, only
+// accepted on WebSocket upgrade requests.
+func websocketToken(r *http.Request) (string, bool) {
+	if !websocket.IsWebSocketUpgrade(r) {
+		return "", false
+	}
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), WebSocketTokenPrefix); ok {
+				return token, true
+			}
+		}
+	}
+	return "", false
+}
+
+// RoleOf returns the role of the request, defaulting to RoleViewer.
+func RoleOf(c echo.Context) Role {
+	if role, ok := c.Get(roleKey).(Role); ok {
+		return role
+	}
+	return RoleViewer
+}
+
+// IsAdmin reports whether the request has the admin role.
+func IsAdmin(c echo.Context) bool {
+	return RoleOf(c) == RoleAdmin
+}
+
+// RequireAdmin rejects requests without the admin role.
+func RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !IsAdmin(c) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "admin role required"})
+		}
+		return next(c)
+	}
+}
@@ -0,0 +1,93 @@
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const root = "/sys/fs/cgroup"
+
+// ErrNotFound is returned when a cgroup cannot be located in the unified
+// hierarchy.
+var ErrNotFound = errors.New("cgroup not found")
+
+// Dir returns the filesystem directory of a cgroup path such as
+// "/system.slice/sshd.service".
+func Dir(cgroup string) (string, error) {
+	// cleaning an absolute path drops any ".." that would escape the root
+	dir := filepath.Join(unifiedRoot(), filepath.Clean("/"+cgroup))
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", ErrNotFound
+	}
+	return dir, nil
+}
+
+// PIDPath returns the cgroup v2 path of a process.
+func PIDPath(pid int) (string, error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return p, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", ErrNotFound
+}
+
+// ContainerPath locates the cgroup of a Docker or Podman container by full
+// or abbreviated ID, covering both the systemd and cgroupfs drivers.
+func ContainerPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/*?[\`) {
+		return "", ErrNotFound
+	}
+	base := unifiedRoot()
+	patterns := []string{
+		"system.slice/docker-" + id + "*.scope",
+		"docker/" + id + "*",
+		"machine.slice/libpod-" + id + "*.scope",
+		"user.slice/user-*.slice/user@*.service/user.slice/libpod-" + id + "*.scope",
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(base, pattern))
+		if err != nil || len(matches) == 0 {
+			continue
+		}
+		return "/" + strings.TrimPrefix(matches[0], base+"/"), nil
+	}
+	return "", ErrNotFound
+}
+
+// Unit returns the systemd service or scope a cgroup path belongs to, or an
+// empty string if it is not managed by systemd.
+func Unit(cgroup string) string {
+	for cgroup != "/" && cgroup != "." && cgroup != "" {
+		name := path.Base(cgroup)
+		if strings.HasSuffix(name, ".service") || strings.HasSuffix(name, ".scope") {
+			return name
+		}
+		cgroup = path.Dir(cgroup)
+	}
+	return ""
+}
+
+// unifiedRoot returns the mount point of the cgroup v2 hierarchy, which is
+// nested under "unified" on hosts running in hybrid mode.
+func unifiedRoot() string {
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		return root
+	}
+	return filepath.Join(root, "unified")
+}
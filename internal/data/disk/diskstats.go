@@ -0,0 +1,137 @@
+package disk
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+)
+
+const (
+	procDiskstats = "/proc/diskstats"
+	sysBlock      = "/sys/block"
+	sectorSize    = 512
+)
+
+// diskstat holds the cumulative counters of one /proc/diskstats line.
+// See https://www.kernel.org/doc/Documentation/ABI/testing/procfs-diskstats
+type diskstat struct {
+	reads          uint64
+	sectorsRead    uint64
+	msReading      uint64
+	writes         uint64
+	sectorsWritten uint64
+	msWriting      uint64
+	msDoingIO      uint64
+}
+
+// Collector samples /proc/diskstats. Rates are derived from the difference
+// between successive calls to Collect.
+type Collector struct {
+	mu       sync.Mutex
+	prev     map[string]diskstat
+	prevTime time.Time
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Collect returns the I/O activity of every whole block device that has
+// completed at least one request. The first call reports zero rates since
+// there is no previous sample to compare with.
+func (c *Collector) Collect(_ context.Context) ([]cayman.DiskIO, error) {
+	stats, names, err := readDiskstats()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := now.Sub(c.prevTime).Seconds()
+	result := make([]cayman.DiskIO, 0, len(names))
+	for _, name := range names {
+		cur := stats[name]
+		d := cayman.DiskIO{Name: name}
+		prev, ok := c.prev[name]
+		if ok && elapsed > 0 {
+			reads := delta(prev.reads, cur.reads)
+			writes := delta(prev.writes, cur.writes)
+			d.ReadBytesPerSec = float64(delta(prev.sectorsRead, cur.sectorsRead)*sectorSize) / elapsed
+			d.WriteBytesPerSec = float64(delta(prev.sectorsWritten, cur.sectorsWritten)*sectorSize) / elapsed
+			d.ReadIOPS = float64(reads) / elapsed
+			d.WriteIOPS = float64(writes) / elapsed
+			if ios := reads + writes; ios > 0 {
+				d.AwaitMs = float64(delta(prev.msReading, cur.msReading)+delta(prev.msWriting, cur.msWriting)) / float64(ios)
+			}
+			d.Utilization = min(100, float64(delta(prev.msDoingIO, cur.msDoingIO))/(elapsed*1000)*100)
+		}
+		result = append(result, d)
+	}
+
+	c.prev = stats
+	c.prevTime = now
+	return result, nil
+}
+
+// delta returns the increase of a cumulative counter, treating a decrease
+// (device re-attached or counter wrap) as no activity.
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// readDiskstats parses /proc/diskstats, keeping only whole devices (those
+// listed under /sys/block) that have seen any I/O. Names are returned in
+// file order.
+func readDiskstats() (map[string]diskstat, []string, error) {
+	f, err := os.Open(procDiskstats)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	stats := make(map[string]diskstat)
+	names := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		name := fields[2]
+		// device names containing a slash are escaped with '!' in sysfs
+		if _, err := os.Stat(filepath.Join(sysBlock, strings.ReplaceAll(name, "/", "!"))); err != nil {
+			continue
+		}
+		var vals [11]uint64
+		for i := range vals {
+			vals[i], _ = strconv.ParseUint(fields[3+i], 10, 64)
+		}
+		ds := diskstat{
+			reads:          vals[0],
+			sectorsRead:    vals[2],
+			msReading:      vals[3],
+			writes:         vals[4],
+			sectorsWritten: vals[6],
+			msWriting:      vals[7],
+			msDoingIO:      vals[9],
+		}
+		if ds.reads == 0 && ds.writes == 0 {
+			continue
+		}
+		stats[name] = ds
+		names = append(names, name)
+	}
+	return stats, names, scanner.Err()
+}
@@ -0,0 +1,199 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+)
+
+const procBootID = "/proc/sys/kernel/random/boot_id"
+
+// kernelScanLines bounds how many kernel messages of a boot are read to find
+// its "Linux version" banner, normally the very first one.
+const kernelScanLines = 50
+
+// kernels caches the kernel release of each boot, which never changes. Boots
+// that have ended are cached even without a release, since their kernel
+// messages will not show up any more.
+var kernels sync.Map
+
+// bootTime matches the times of the text output of --list-boots, e.g.
+// "Mon 2024-01-01 10:00:00 UTC".
+var bootTime = regexp.MustCompile(`\w{3} (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) UTC`)
+
+// ListBoots returns the boots recorded in the journal, newest first.
+func ListBoots(ctx context.Context) ([]cayman.Boot, error) {
+	boots, err := listBoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range boots {
+		boots[i].Kernel = kernel(ctx, boots[i].ID, boots[i].Index < 0)
+	}
+	return boots, nil
+}
+
+// listBoots runs journalctl --list-boots. JSON output needs systemd 251 or
+// later, older versions ignore --output or reject it, so their text output
+// is parsed instead.
+func listBoots(ctx context.Context) ([]cayman.Boot, error) {
+	out, err := runListBoots(ctx, "--output", "json")
+	if err != nil {
+		if out, err = runListBoots(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var boots []struct {
+		Index      int    `json:"index"`
+		BootID     string `json:"boot_id"`
+		FirstEntry int64  `json:"first_entry"`
+		LastEntry  int64  `json:"last_entry"`
+	}
+	if err := json.Unmarshal(out, &boots); err != nil {
+		return parseBootsText(out)
+	}
+
+	result := make([]cayman.Boot, 0, len(boots))
+	for _, b := range slices.Backward(boots) {
+		result = append(result, cayman.Boot{
+			Index:      b.Index,
+			ID:         b.BootID,
+			FirstEntry: time.UnixMicro(b.FirstEntry),
+			LastEntry:  time.UnixMicro(b.LastEntry),
+		})
+	}
+	return result, nil
+}
+
+func runListBoots(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "journalctl", append([]string{"--list-boots", "--no-pager", "--quiet"}, args...)...)
+	// text output prints times in the local zone with an abbreviation that
+	// cannot be parsed reliably
+	cmd.Env = append(os.Environ(), "TZ=UTC", "LC_ALL=C")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(err)
+	}
+	return out, nil
+}
+
+// parseBootsText parses the text output of --list-boots, oldest first, into
+// boots newest first. Lines are "IDX BOOT_ID FIRST_ENTRY LAST_ENTRY", after
+// a header line on some versions.
+func parseBootsText(out []byte) ([]cayman.Boot, error) {
+	result := make([]cayman.Boot, 0)
+	for _, line := range slices.Backward(strings.Split(string(out), "\n")) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			// the header
+			continue
+		}
+		times := bootTime.FindAllStringSubmatch(line, 2)
+		if len(times) != 2 {
+			return nil, fmt.Errorf("unexpected journalctl --list-boots line %q", line)
+		}
+		boot := cayman.Boot{
+			Index: index,
+			ID:    fields[1],
+		}
+		boot.FirstEntry, _ = time.Parse(time.DateTime, times[0][1])
+		boot.LastEntry, _ = time.Parse(time.DateTime, times[1][1])
+		result = append(result, boot)
+	}
+	return result, nil
+}
+
+// CurrentBootID returns the ID of the running boot in the journal's format,
+// without dashes.
+func CurrentBootID() (string, error) {
+	bb, err := os.ReadFile(procBootID)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(strings.TrimSpace(string(bb)), "-", ""), nil
+}
+
+// kernel returns the kernel release of a boot from its first kernel
+// messages, e.g. "6.1.0-18-amd64" from "Linux version 6.1.0-18-amd64 (...)".
+// ended tells whether the boot is over, so a missing release is cached too.
+func kernel(ctx context.Context, bootID string, ended bool) string {
+	if v, ok := kernels.Load(bootID); ok {
+		return v.(string)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(ctx, "journalctl", jsonArgs("_BOOT_ID="+bootID, "_TRANSPORT=kernel")...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return ""
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return ""
+	}
+	// stop journalctl once the banner is found
+	defer func() {
+		cancel()
+		_ = cmd.Wait()
+	}()
+
+	var release string
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	for i := 0; i < kernelScanLines && scanner.Scan(); i++ {
+		e, err := decode(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(e.Message, "Linux version "); ok {
+			release, _, _ = strings.Cut(rest, " ")
+			break
+		}
+	}
+	// a request that went away leaves the scan incomplete
+	if release != "" || (ended && scanner.Err() == nil && ctx.Err() == nil) {
+		kernels.Store(bootID, release)
+	}
+	return release
+}
+
+// resolveBoot turns a boot offset such as 0 or -1 into a boot ID. Anything
+// else is taken to be a boot ID already.
+func resolveBoot(ctx context.Context, boot string) (string, error) {
+	offset, err := strconv.Atoi(boot)
+	if err != nil {
+		return boot, nil
+	}
+	if offset > 0 {
+		return "", fmt.Errorf("invalid boot offset %d, offsets count back from the current boot 0", offset)
+	}
+	if offset == 0 {
+		return CurrentBootID()
+	}
+	boots, err := listBoots(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, b := range boots {
+		if b.Index == offset {
+			return b.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no boot with offset %d", offset)
+}
@@ -0,0 +1,242 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"cayman"
+)
+
+const (
+	// DefaultLimit is the page size used when a query sets none.
+	DefaultLimit = 100
+	// MaxLimit bounds the page size of a query.
+	MaxLimit = 1000
+
+	// maxEntrySize bounds a single JSON encoded entry, journald accepts
+	// fields up to 64 MiB but anything close to that is not worth showing.
+	maxEntrySize = 4 * 1024 * 1024
+)
+
+// Query selects a page of journal entries, newest first.
+type Query struct {
+	Filter
+	// Cursor continues a previous query with the entries older than the one
+	// it identifies.
+	Cursor string
+	Limit  int
+}
+
+// Read returns a page of entries matching q. The journal is read through
+// journalctl, which keeps the binary free of a libsystemd build dependency.
+func Read(ctx context.Context, q Query) (cayman.LogPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	q.Limit = min(q.Limit, MaxLimit)
+
+	args := []string{"--reverse"}
+	if q.Message == nil {
+		// one extra entry tells whether an older page exists
+		args = append(args, "--lines", strconv.Itoa(q.Limit+1))
+	}
+	if q.Cursor != "" {
+		args = append(args, "--after-cursor", q.Cursor)
+	}
+	args = append(args, q.Filter.args()...)
+
+	page := cayman.LogPage{Entries: make([]cayman.LogEntry, 0, q.Limit)}
+	err := scan(ctx, jsonArgs(args...), func(line []byte) bool {
+		e, err := decode(line)
+		if err != nil || !q.matchMessage(e) {
+			return true
+		}
+		page.Entries = append(page.Entries, e)
+		return len(page.Entries) <= q.Limit
+	})
+	if err != nil {
+		return cayman.LogPage{}, err
+	}
+	if len(page.Entries) > q.Limit {
+		page.Entries = page.Entries[:q.Limit]
+		page.Next = page.Entries[q.Limit-1].Cursor
+	}
+	return page, nil
+}
+
+// Follow calls fn with every entry appended to the journal after cursor, or
+// after the current end of the journal when cursor is empty, until ctx is
+// done or journalctl exits.
+func Follow(ctx context.Context, cursor string, fn func(cayman.LogEntry)) error {
+	args := []string{"--follow"}
+	if cursor != "" {
+		args = append(args, "--after-cursor", cursor)
+	} else {
+		args = append(args, "--lines", "0")
+	}
+	err := scan(ctx, jsonArgs(args...), func(line []byte) bool {
+		if e, err := decode(line); err == nil {
+			fn(e)
+		}
+		return true
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// scan runs journalctl with args and calls fn with every line of its
+// output until fn returns false, which stops journalctl.
+func scan(ctx context.Context, args []string, fn func(line []byte) bool) error {
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	stopped := false
+	for scanner.Scan() {
+		if !fn(scanner.Bytes()) {
+			stopped = true
+			break
+		}
+	}
+	scanErr := scanner.Err()
+	// kill journalctl if it was stopped or the scanner gave up before it
+	// exited
+	_ = cmd.Cancel()
+	if err := cmd.Wait(); err != nil && !stopped && scanErr == nil {
+		return stderrError(err, stderr.Bytes())
+	}
+	return scanErr
+}
+
+func jsonArgs(args ...string) []string {
+	return append([]string{"--output", "json", "--no-pager", "--quiet"}, args...)
+}
+
+// commandError adds the stderr of a failed journalctl to its error.
+func commandError(err error) error {
+	if ee, ok := err.(*exec.ExitError); ok {
+		return stderrError(err, ee.Stderr)
+	}
+	return err
+}
+
+// stderrError adds stderr, when there is any, to the error of a failed
+// journalctl.
+func stderrError(err error, stderr []byte) error {
+	if stderr = bytes.TrimSpace(stderr); len(stderr) > 0 {
+		return fmt.Errorf("journalctl: %s", stderr)
+	}
+	return err
+}
+
+// record is an entry as printed by journalctl --output json. Values are
+// strings, arrays of bytes for binary data, or arrays of values for fields
+// set more than once.
+type record map[string]json.RawMessage
+
+func (r record) str(key string) string {
+	raw, ok := r[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var binary []int
+	if json.Unmarshal(raw, &binary) == nil {
+		bb := make([]byte, len(binary))
+		for i, b := range binary {
+			bb[i] = byte(b)
+		}
+		return string(bb)
+	}
+	var values []json.RawMessage
+	if json.Unmarshal(raw, &values) == nil && len(values) > 0 {
+		return record{key: values[0]}.str(key)
+	}
+	return ""
+}
+
+// values returns every value of a field, which is set more than once when
+// it is an array of values.
+func (r record) values(key string) []string {
+	var values []json.RawMessage
+	if json.Unmarshal(r[key], &values) != nil || len(values) == 0 {
+		if s := r.str(key); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+	if _, err := strconv.Atoi(string(values[0])); err == nil {
+		// an array of bytes, binary data
+		return []string{r.str(key)}
+	}
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, record{key: v}.str(key))
+	}
+	return out
+}
+
+func (r record) int(key string) (int, bool) {
+	v, err := strconv.Atoi(r.str(key))
+	return v, err == nil
+}
+
+func decode(line []byte) (cayman.LogEntry, error) {
+	var r record
+	if err := json.Unmarshal(line, &r); err != nil {
+		return cayman.LogEntry{}, err
+	}
+	return r.entry(), nil
+}
+
+func (r record) entry() cayman.LogEntry {
+	e := cayman.LogEntry{
+		Cursor:     r.str("__CURSOR"),
+		Hostname:   r.str("_HOSTNAME"),
+		Identifier: r.str("SYSLOG_IDENTIFIER"),
+		Unit:       r.str("_SYSTEMD_UNIT"),
+		UserUnit:   r.str("_SYSTEMD_USER_UNIT"),
+		BootID:     r.str("_BOOT_ID"),
+		Transport:  r.str("_TRANSPORT"),
+		Facility:   facilityName(r.str("SYSLOG_FACILITY")),
+		Container:  r.str("CONTAINER_NAME"),
+		Message:    r.str("MESSAGE"),
+		// journald's default for entries without a priority
+		Priority: 6,
+	}
+	if e.Identifier == "" {
+		e.Identifier = r.str("_COMM")
+	}
+	if usec, err := strconv.ParseInt(r.str("__REALTIME_TIMESTAMP"), 10, 64); err == nil {
+		e.Time = time.UnixMicro(usec)
+	}
+	if prio, ok := r.int("PRIORITY"); ok {
+		e.Priority = prio
+	}
+	if pid, ok := r.int("_PID"); ok {
+		e.PID = int32(pid)
+	} else if pid, ok := r.int("SYSLOG_PID"); ok {
+		e.PID = int32(pid)
+	}
+	return e
+}
@@ -0,0 +1,294 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+)
+
+// Export formats.
+const (
+	FormatText   = "text"   // journalctl's short-iso output
+	FormatJSON   = "json"   // one cayman.LogEntry per line
+	FormatExport = "export" // journal export format, importable with systemd-journal-remote
+)
+
+// Formats lists the accepted export formats.
+var Formats = []string{FormatText, FormatJSON, FormatExport}
+
+// Export is a running export of the journal entries matching a filter.
+type Export struct {
+	cmd    *exec.Cmd
+	out    *bufio.Reader
+	stderr bytes.Buffer
+	output string
+	format string
+	filter Filter
+	// exited is set when journalctl exited before writing anything
+	exited bool
+}
+
+// StartExport starts journalctl for an export of the entries matching f and
+// waits for its first output or its exit, so that a failing query is
+// reported before anything is written. Write must be called when it
+// succeeds.
+func StartExport(ctx context.Context, f Filter, format string) (*Export, error) {
+	x := &Export{format: format, filter: f}
+	switch format {
+	case FormatText:
+		x.output = "short-iso"
+	case FormatJSON:
+		x.output = "json"
+	case FormatExport:
+		x.output = "export"
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if f.Message != nil {
+		// the pattern is matched here, the entries are then written from
+		// their JSON form
+		x.output = "json"
+	}
+
+	args := append([]string{"--output", x.output, "--no-pager", "--quiet"}, f.args()...)
+	x.cmd = exec.CommandContext(ctx, "journalctl", args...)
+	x.cmd.Stderr = &x.stderr
+	stdout, err := x.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := x.cmd.Start(); err != nil {
+		return nil, err
+	}
+	x.out = bufio.NewReader(stdout)
+	if _, err := x.out.Peek(1); err != nil {
+		x.exited = true
+		if err := x.cmd.Wait(); err != nil {
+			return nil, stderrError(err, x.stderr.Bytes())
+		}
+	}
+	return x, nil
+}
+
+// Write writes the entries to w, oldest first, preceded by the header.
+// Entries are streamed from journalctl as they are read, so the export is
+// never held in memory.
+func (x *Export) Write(w io.Writer, header cayman.LogExportHeader) error {
+	err := writeHeader(w, x.format, header)
+	if err == nil {
+		if x.output == "json" {
+			err = reencode(w, x.out, x.format, x.filter)
+		} else {
+			_, err = io.Copy(w, x.out)
+		}
+	}
+	if x.exited {
+		return err
+	}
+	if err != nil {
+		// the client went away, stop journalctl
+		_ = x.cmd.Cancel()
+		_ = x.cmd.Wait()
+		return err
+	}
+	if err := x.cmd.Wait(); err != nil {
+		return stderrError(err, x.stderr.Bytes())
+	}
+	return nil
+}
+
+// WriteEntries writes entries, oldest first, preceded by the header in the
+// same formats as an Export. It serves entries that are not in the journal,
+// such as those of tailed log files.
+func WriteEntries(w io.Writer, entries []cayman.LogEntry, format string, header cayman.LogExportHeader) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err := writeHeader(w, format, header); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if e.Hostname == "" {
+			// entries of tailed files come from the exporting host
+			e.Hostname = header.Hostname
+		}
+		var err error
+		switch format {
+		case FormatText:
+			err = writeTextEntry(w, e)
+		case FormatJSON:
+			err = enc.Encode(e)
+		case FormatExport:
+			err = writeExportEntry(w, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTextEntry writes an entry like journalctl's short-iso output.
+func writeTextEntry(w io.Writer, e cayman.LogEntry) error {
+	_, err := fmt.Fprintf(w, "%s %s %s: %s\n", e.Time.Format("2006-01-02T15:04:05-0700"), e.Hostname, tag(e), e.Message)
+	return err
+}
+
+// tag formats the identifier and PID of an entry like journalctl does.
+func tag(e cayman.LogEntry) string {
+	if e.PID > 0 {
+		return e.Identifier + "[" + strconv.Itoa(int(e.PID)) + "]"
+	}
+	return e.Identifier
+}
+
+// writeExportEntry writes an entry in the journal export format.
+func writeExportEntry(w io.Writer, e cayman.LogEntry) error {
+	fields := [][2]string{
+		{"__REALTIME_TIMESTAMP", strconv.FormatInt(e.Time.UnixMicro(), 10)},
+		{"_HOSTNAME", e.Hostname},
+		{"SYSLOG_IDENTIFIER", e.Identifier},
+		{"PRIORITY", strconv.Itoa(e.Priority)},
+		{"_TRANSPORT", e.Transport},
+		{"CAYMAN_SOURCE", e.Source},
+		{"MESSAGE", e.Message},
+	}
+	if e.PID > 0 {
+		fields = append(fields, [2]string{"_PID", strconv.Itoa(int(e.PID))})
+	}
+	var buf bytes.Buffer
+	for _, field := range fields {
+		if field[1] != "" {
+			writeExportField(&buf, field[0], field[1])
+		}
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeExportRecord writes every field of a journalctl JSON record in the
+// journal export format, like journalctl --output export.
+func writeExportRecord(w io.Writer, r record) error {
+	var buf bytes.Buffer
+	for _, key := range slices.Sorted(maps.Keys(r)) {
+		for _, value := range r.values(key) {
+			writeExportField(&buf, key, value)
+		}
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeExportField writes a field of the journal export format. Values with
+// a newline use the binary form, a little-endian length followed by the
+// data.
+func writeExportField(buf *bytes.Buffer, key, value string) {
+	if !strings.Contains(value, "\n") {
+		buf.WriteString(key + "=" + value + "\n")
+		return
+	}
+	buf.WriteString(key + "\n")
+	buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(value))))
+	buf.WriteString(value + "\n")
+}
+
+// reencode converts journalctl's JSON output to format, keeping the entries
+// whose message matches f.
+func reencode(w io.Writer, r io.Reader, format string, f Filter) error {
+	enc := json.NewEncoder(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		e := rec.entry()
+		if !f.matchMessage(e) {
+			continue
+		}
+		var err error
+		switch format {
+		case FormatText:
+			err = writeTextEntry(w, e)
+		case FormatJSON:
+			err = enc.Encode(e)
+		case FormatExport:
+			err = writeExportRecord(w, rec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// writeHeader writes the header in a form that keeps the export valid:
+// comment lines for text, a first JSON line, or a leading journal entry.
+func writeHeader(w io.Writer, format string, h cayman.LogExportHeader) error {
+	switch format {
+	case FormatText:
+		lines := []string{
+			"cayman log export",
+			"exported at: " + h.ExportedAt.Format(time.RFC3339),
+			"query: " + h.Query,
+			"host: " + h.Hostname,
+			"fqdn: " + h.FQDN,
+		}
+		if osInfo := h.HostInfo.OS; osInfo != nil {
+			lines = append(lines, "os: "+osInfo.Name+" "+osInfo.Version)
+		}
+		lines = append(lines,
+			"kernel: "+h.HostInfo.KernelVersion,
+			"architecture: "+h.HostInfo.Architecture,
+			"boot time: "+h.HostInfo.BootTime.Format(time.RFC3339),
+		)
+		for _, line := range lines {
+			if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(h)
+	case FormatExport:
+		bb, err := json.Marshal(h)
+		if err != nil {
+			return err
+		}
+		// compact JSON has no newlines, so it fits a plain text field
+		fields := []string{
+			"__REALTIME_TIMESTAMP=" + strconv.FormatInt(h.ExportedAt.UnixMicro(), 10),
+			"_HOSTNAME=" + h.Hostname,
+			"SYSLOG_IDENTIFIER=cayman",
+			"PRIORITY=6",
+			"MESSAGE=cayman log export of " + h.Hostname,
+			"CAYMAN_EXPORT_HEADER=" + string(bb),
+		}
+		for _, field := range fields {
+			if _, err := io.WriteString(w, field+"\n"); err != nil {
+				return err
+			}
+		}
+		// entries are separated by an empty line
+		_, err = io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+)
+
+// priorities are the syslog level names accepted by journalctl, in order.
+var priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Filter selects journal entries. Empty fields match every entry and set
+// fields must all match.
+type Filter struct {
+	Unit       string // system unit that logged the entry, _SYSTEMD_UNIT
+	Identifier string // SYSLOG_IDENTIFIER
+	Container  string // CONTAINER_NAME, set by the docker and podman journald drivers
+	Transport  string // _TRANSPORT, e.g. kernel
+	Source     string // file path of entries tailed from log files
+	BootID     string
+	PID        int32
+	// Priority keeps entries of this level or more severe. It is only
+	// applied when HasPriority is set, since 0 (emerg) is a valid level.
+	Priority    int
+	HasPriority bool
+	Since       time.Time
+	Until       time.Time
+	// Message is matched against MESSAGE, by cayman rather than journalctl
+	// so queries and live entries use the same Go regexp syntax.
+	Message *regexp.Regexp
+}
+
+// ParseFilter reads a filter from the query params unit, identifier,
+// container, transport, source, boot (an ID, or an offset such as 0 for the current boot and -1
+// for the one before), pid, priority (a level name or number), since and
+// until (RFC 3339) and message (a regular expression in Go's RE2 syntax).
+func ParseFilter(ctx context.Context, v url.Values) (Filter, error) {
+	f := Filter{
+		Unit:       v.Get("unit"),
+		Identifier: v.Get("identifier"),
+		Container:  v.Get("container"),
+		Transport:  v.Get("transport"),
+		Source:     v.Get("source"),
+	}
+	if s := v.Get("boot"); s != "" {
+		id, err := resolveBoot(ctx, s)
+		if err != nil {
+			return f, err
+		}
+		f.BootID = id
+	}
+	if s := v.Get("pid"); s != "" {
+		pid, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || pid <= 0 {
+			return f, fmt.Errorf("invalid pid %q", s)
+		}
+		f.PID = int32(pid)
+	}
+	if s := v.Get("priority"); s != "" {
+		prio, err := parsePriority(s)
+		if err != nil {
+			return f, err
+		}
+		f.Priority, f.HasPriority = prio, true
+	}
+	for _, t := range []struct {
+		param string
+		value *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		if s := v.Get(t.param); s != "" {
+			ts, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s, expected RFC 3339: %w", t.param, err)
+			}
+			*t.value = ts
+		}
+	}
+	if s := v.Get("message"); s != "" {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return f, fmt.Errorf("invalid message pattern: %w", err)
+		}
+		f.Message = re
+	}
+	return f, nil
+}
+
+func parsePriority(s string) (int, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(priorities) {
+		return n, nil
+	}
+	for i, name := range priorities {
+		if strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid priority %q, expected 0-7 or one of %s", s, strings.Join(priorities, ", "))
+}
+
+// Match reports whether an entry passes the filter. It is used for live
+// entries; queries pass the filter to journalctl instead, except Message.
+func (f Filter) Match(e cayman.LogEntry) bool {
+	switch {
+	case f.Unit != "" && e.Unit != f.Unit,
+		f.Identifier != "" && e.Identifier != f.Identifier,
+		f.Container != "" && e.Container != f.Container,
+		f.Transport != "" && e.Transport != f.Transport,
+		f.Source != "" && e.Source != f.Source,
+		f.BootID != "" && e.BootID != f.BootID,
+		f.PID != 0 && e.PID != f.PID,
+		f.HasPriority && e.Priority > f.Priority,
+		!f.Since.IsZero() && e.Time.Before(f.Since),
+		!f.Until.IsZero() && e.Time.After(f.Until),
+		!f.matchMessage(e):
+		return false
+	}
+	return true
+}
+
+// matchMessage reports whether the message of an entry matches the Message
+// pattern. Queries match it here rather than with journalctl --grep, whose
+// PCRE2 patterns differ from Go's and which some builds lack.
+func (f Filter) matchMessage(e cayman.LogEntry) bool {
+	return f.Message == nil || f.Message.MatchString(e.Message)
+}
+
+// args returns the journalctl arguments applying the filter but Message,
+// see matchMessage. Field matches and time bounds use the journal's indexes,
+// so only matching entries are read from disk.
+func (f Filter) args() []string {
+	var args []string
+	if f.HasPriority {
+		args = append(args, "--priority", strconv.Itoa(f.Priority))
+	}
+	if !f.Since.IsZero() {
+		args = append(args, "--since", "@"+strconv.FormatInt(f.Since.Unix(), 10))
+	}
+	if !f.Until.IsZero() {
+		// round up, journalctl only takes whole seconds
+		args = append(args, "--until", "@"+strconv.FormatInt(f.Until.Add(time.Second-1).Unix(), 10))
+	}
+	// matches go last, after all options
+	if f.Unit != "" {
+		args = append(args, "_SYSTEMD_UNIT="+f.Unit)
+	}
+	if f.Identifier != "" {
+		args = append(args, "SYSLOG_IDENTIFIER="+f.Identifier)
+	}
+	if f.Container != "" {
+		args = append(args, "CONTAINER_NAME="+f.Container)
+	}
+	if f.Transport != "" {
+		args = append(args, "_TRANSPORT="+f.Transport)
+	}
+	if f.BootID != "" {
+		args = append(args, "_BOOT_ID="+f.BootID)
+	}
+	if f.PID != 0 {
+		args = append(args, "_PID="+strconv.Itoa(int(f.PID)))
+	}
+	return args
+}
@@ -0,0 +1,34 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"cayman"
+)
+
+// UnitTail returns the last n journal lines logged by a systemd unit.
+func UnitTail(ctx context.Context, scope cayman.UnitScope, unit string, n int) ([]string, error) {
+	flag := "--unit"
+	if scope == cayman.UnitScopeUser {
+		flag = "--user-unit"
+	}
+	out, err := exec.CommandContext(ctx, "journalctl",
+		flag, unit,
+		"--lines", strconv.Itoa(n),
+		"--output", "short-iso",
+		"--no-pager",
+		"--quiet",
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return []string{}, nil
+	}
+	return strings.Split(string(out), "\n"), nil
+}
@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"cayman"
+)
+
+// TransportKernel is the _TRANSPORT of messages read from the kernel ring
+// buffer.
+const TransportKernel = "kernel"
+
+// facilities are the syslog facility names, indexed by SYSLOG_FACILITY.
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// facilityName maps a SYSLOG_FACILITY number to its name, leaving unknown
+// values as they are.
+func facilityName(s string) string {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(facilities) {
+		return facilities[n]
+	}
+	return s
+}
+
+// KernelEvent is a kernel message reporting a problem worth a warning.
+type KernelEvent struct {
+	Kind    string // oom, io, segfault or link
+	Subject string // the process, device or interface concerned
+	Summary string
+}
+
+// kernelPatterns recognize common problems in kernel messages. The first
+// submatch is the subject of the event.
+var kernelPatterns = []struct {
+	kind    string
+	re      *regexp.Regexp
+	summary string
+}{
+	// "Out of memory: Killed process 1234 (java) ..." and the same from a
+	// memory cgroup
+	{"oom", regexp.MustCompile(`(?i)out of memory: Killed process \d+ \(([^)]*)\)`), "out of memory, killed %s"},
+	// "I/O error, dev sda, sector 2048 ..." and "Buffer I/O error on dev sda1, ..."
+	{"io", regexp.MustCompile(`I/O error,? (?:on )?dev (\w+)`), "I/O error on %s"},
+	// "nginx[1234]: segfault at 0 ip ..."
+	{"segfault", regexp.MustCompile(`(\S+)\[\d+\]: segfault at`), "%s segfaulted"},
+	// "enp2s0: Link is Down" and "e1000e: eth0 NIC Link is Down"
+	{"link", regexp.MustCompile(`(\S+?):? (?:NIC )?Link is Down`), "network link %s is down"},
+}
+
+// DetectKernelEvent reports whether a kernel message matches one of the
+// known problem patterns.
+func DetectKernelEvent(e cayman.LogEntry) (KernelEvent, bool) {
+	if e.Transport != TransportKernel {
+		return KernelEvent{}, false
+	}
+	for _, p := range kernelPatterns {
+		m := p.re.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		return KernelEvent{
+			Kind:    p.kind,
+			Subject: m[1],
+			Summary: "kernel: " + fmt.Sprintf(p.summary, m[1]),
+		}, true
+	}
+	return KernelEvent{}, false
+}
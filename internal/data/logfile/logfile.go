@@ -0,0 +1,202 @@
+package logfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+)
+
+// TransportFile is the transport of entries read from plain-text log files.
+const TransportFile = "file"
+
+// Formats of a Source.
+const (
+	FormatPlain = ""      // every line is the message
+	FormatRegex = "regex" // fields are taken from the named groups of Pattern
+	FormatJSON  = "json"  // every line is a JSON object
+)
+
+// Source is a set of log files to tail and how to parse their lines.
+type Source struct {
+	// Glob selects the files, e.g. /var/log/myapp/*.log. It is evaluated
+	// again on every poll, so files created later are picked up.
+	Glob   string `json:"glob"`
+	Format string `json:"format"`
+	// Pattern is the regular expression of the regex format. The named
+	// groups time, priority, identifier, pid and message fill the matching
+	// entry fields; lines that do not match are kept whole as the message.
+	Pattern string `json:"pattern,omitempty"`
+	// TimeLayout parses the time field, in Go's layout syntax. RFC 3339 is
+	// used when empty.
+	TimeLayout string `json:"time_layout,omitempty"`
+	// Identifier names the entries of this source when they carry no
+	// identifier of their own, the file name without extension by default.
+	Identifier string `json:"identifier,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// jsonKeys are the keys looked up, in order, for each field of a JSON line.
+var jsonKeys = struct {
+	time, priority, identifier, pid, message []string
+}{
+	time:       []string{"time", "timestamp", "ts", "@timestamp"},
+	priority:   []string{"level", "severity", "priority", "lvl"},
+	identifier: []string{"identifier", "app", "logger", "name"},
+	pid:        []string{"pid"},
+	message:    []string{"message", "msg"},
+}
+
+// levels maps level names used by common logging libraries to syslog
+// priorities.
+var levels = map[string]int{
+	"emerg": 0, "emergency": 0, "panic": 0,
+	"alert": 1,
+	"crit":  2, "critical": 2, "fatal": 2,
+	"err": 3, "error": 3,
+	"warn": 4, "warning": 4,
+	"notice": 5,
+	"info":   6, "information": 6,
+	"debug": 7, "trace": 7,
+}
+
+// LoadSources reads a JSON array of sources from a file.
+func LoadSources(name string) ([]Source, error) {
+	bb, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var sources []Source
+	if err := json.Unmarshal(bb, &sources); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	for i := range sources {
+		if err := sources[i].compile(); err != nil {
+			return nil, fmt.Errorf("source %s: %w", sources[i].Glob, err)
+		}
+	}
+	return sources, nil
+}
+
+func (s *Source) compile() error {
+	if s.Glob == "" {
+		return errors.New("glob is required")
+	}
+	if _, err := filepath.Match(s.Glob, ""); err != nil {
+		return err
+	}
+	switch s.Format {
+	case FormatPlain, FormatJSON:
+	case FormatRegex:
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.re = re
+	default:
+		return fmt.Errorf("unknown format %q, expected regex or json", s.Format)
+	}
+	return nil
+}
+
+// parse turns a line of a file into an entry. The time of the entry is the
+// time it was read unless the line carries one.
+func (s *Source) parse(path, line string, read time.Time) cayman.LogEntry {
+	e := cayman.LogEntry{
+		Time:       read,
+		Priority:   6,
+		Identifier: s.Identifier,
+		Transport:  TransportFile,
+		Source:     path,
+		Message:    line,
+	}
+	if e.Identifier == "" {
+		base := filepath.Base(path)
+		e.Identifier = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	fields := make(map[string]string)
+	switch s.Format {
+	case FormatRegex:
+		m := s.re.FindStringSubmatch(line)
+		if m == nil {
+			return e
+		}
+		for i, name := range s.re.SubexpNames() {
+			if name != "" && m[i] != "" {
+				fields[name] = m[i]
+			}
+		}
+	case FormatJSON:
+		var obj map[string]any
+		dec := json.NewDecoder(strings.NewReader(line))
+		// keep numbers such as PIDs as written
+		dec.UseNumber()
+		if dec.Decode(&obj) != nil {
+			return e
+		}
+		lookup := func(field string, keys []string) {
+			for _, key := range keys {
+				if v, ok := obj[key]; ok {
+					fields[field] = fmt.Sprint(v)
+					return
+				}
+			}
+		}
+		lookup("time", jsonKeys.time)
+		lookup("priority", jsonKeys.priority)
+		lookup("identifier", jsonKeys.identifier)
+		lookup("pid", jsonKeys.pid)
+		lookup("message", jsonKeys.message)
+	default:
+		return e
+	}
+
+	if v, ok := fields["message"]; ok {
+		e.Message = v
+	}
+	if v, ok := fields["identifier"]; ok {
+		e.Identifier = v
+	}
+	if v, ok := fields["pid"]; ok {
+		if pid, err := strconv.ParseInt(v, 10, 32); err == nil {
+			e.PID = int32(pid)
+		}
+	}
+	if v, ok := fields["priority"]; ok {
+		if prio, ok := parseLevel(v); ok {
+			e.Priority = prio
+		}
+	}
+	if v, ok := fields["time"]; ok {
+		layout := s.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		// layouts without a zone are in local time, as most log files are
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			if t.Year() == 0 {
+				// syslog style timestamps omit the year
+				t = t.AddDate(read.Year(), 0, 0)
+			}
+			e.Time = t
+		}
+	}
+	return e
+}
+
+func parseLevel(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 7 {
+		return n, true
+	}
+	prio, ok := levels[strings.ToLower(s)]
+	return prio, ok
+}
@@ -0,0 +1,204 @@
+package logfile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"cayman"
+)
+
+// maxLineSize bounds a single line, longer lines are split.
+const maxLineSize = 64 * 1024
+
+// Tailer follows the files of a set of sources, surviving rotation by rename
+// (a new inode at the same path) and by truncation.
+type Tailer struct {
+	sources []Source
+	files   map[string]*file
+	started bool
+}
+
+type file struct {
+	source  *Source
+	f       *os.File
+	ino     uint64
+	offset  int64
+	partial []byte
+}
+
+func NewTailer(sources []Source) *Tailer {
+	return &Tailer{
+		sources: sources,
+		files:   make(map[string]*file),
+	}
+}
+
+// Poll reads the lines appended since the previous call and passes them to
+// fn. Files present on the first call are read from their end, files that
+// appear later from their start. A file renamed to another path matching the
+// globs, e.g. app.log to app.log.1, is followed at its new path rather than
+// read again.
+func (t *Tailer) Poll(fn func(cayman.LogEntry)) {
+	seen := make(map[string]*Source)
+	var paths []string
+	for i := range t.sources {
+		s := &t.sources[i]
+		matches, _ := filepath.Glob(s.Glob)
+		for _, path := range matches {
+			if _, ok := seen[path]; !ok {
+				seen[path] = s
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	inodes := make(map[uint64]string, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			inodes[inode(info)] = path
+		}
+	}
+	for path, f := range t.files {
+		moved, ok := inodes[f.ino]
+		if !ok || moved == path {
+			continue
+		}
+		if _, tailed := t.files[moved]; !tailed {
+			delete(t.files, path)
+			t.files[moved] = f
+		}
+	}
+
+	for _, path := range paths {
+		if _, ok := t.files[path]; !ok {
+			f, err := open(seen[path], path, !t.started)
+			if err != nil {
+				slog.Error("failed to open log file", "path", path, "error", err)
+				continue
+			}
+			t.files[path] = f
+		}
+	}
+	t.started = true
+
+	for path, f := range t.files {
+		f.read(path, fn)
+		info, err := os.Stat(path)
+		switch {
+		case err != nil || seen[path] == nil:
+			// removed, or renamed away without a replacement yet; the rest of
+			// the file was read above
+			f.close(path, fn)
+			delete(t.files, path)
+		case inode(info) != f.ino:
+			// rotated: the old file was drained above, continue with the new
+			// one from its start
+			f.close(path, fn)
+			nf, err := open(f.source, path, false)
+			if err != nil {
+				delete(t.files, path)
+				continue
+			}
+			t.files[path] = nf
+			nf.read(path, fn)
+		case info.Size() < f.offset:
+			// truncated in place, e.g. by copytruncate
+			f.offset = 0
+			f.partial = nil
+			if _, err := f.f.Seek(0, io.SeekStart); err == nil {
+				f.read(path, fn)
+			}
+		}
+	}
+}
+
+// Close closes all files.
+func (t *Tailer) Close() {
+	for path, f := range t.files {
+		f.f.Close()
+		delete(t.files, path)
+	}
+}
+
+func open(s *Source, path string, atEnd bool) (*file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	tf := &file{source: s, f: f, ino: inode(info)}
+	if atEnd {
+		if tf.offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return tf, nil
+}
+
+// read passes every complete line appended to the file to fn, keeping an
+// incomplete last line for the next read.
+func (f *file) read(path string, fn func(cayman.LogEntry)) {
+	buf := make([]byte, 32*1024)
+	now := time.Now()
+	for {
+		n, err := f.f.Read(buf)
+		f.offset += int64(n)
+		f.partial = append(f.partial, buf[:n]...)
+		for {
+			i := bytes.IndexByte(f.partial, '\n')
+			if i < 0 {
+				break
+			}
+			f.emit(path, f.partial[:i], now, fn)
+			f.partial = f.partial[i+1:]
+		}
+		if len(f.partial) >= maxLineSize {
+			f.emit(path, f.partial, now, fn)
+			f.partial = nil
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				slog.Error("failed to read log file", "path", path, "error", err)
+			}
+			// copy so the partial line does not pin the read buffer
+			f.partial = bytes.Clone(f.partial)
+			return
+		}
+	}
+}
+
+// close emits a last line left without a trailing newline and closes the
+// file.
+func (f *file) close(path string, fn func(cayman.LogEntry)) {
+	if len(f.partial) > 0 {
+		f.emit(path, f.partial, time.Now(), fn)
+		f.partial = nil
+	}
+	f.f.Close()
+}
+
+func (f *file) emit(path string, line []byte, now time.Time, fn func(cayman.LogEntry)) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return
+	}
+	fn(f.source.parse(path, string(line), now))
+}
+
+func inode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Ino
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cayman"
+)
+
+const (
+	procMeminfo  = "/proc/meminfo"
+	procVmstat   = "/proc/vmstat"
+	procSwaps    = "/proc/swaps"
+	sysBlock     = "/sys/block"
+	zswapEnabled = "/sys/module/zswap/parameters/enabled"
+)
+
+// Detail returns the memory breakdown of the host. Only /proc/meminfo is
+// required; the remaining sources are skipped when unavailable.
+func Detail() (cayman.MemoryDetail, error) {
+	mi, err := readKeyed(procMeminfo, ":")
+	if err != nil {
+		return cayman.MemoryDetail{}, err
+	}
+	// meminfo values are in kB, except the HugePages_ counts
+	kb := func(key string) uint64 { return mi[key] * 1024 }
+
+	d := cayman.MemoryDetail{
+		Total:        kb("MemTotal"),
+		Free:         kb("MemFree"),
+		Available:    kb("MemAvailable"),
+		Buffers:      kb("Buffers"),
+		Cached:       kb("Cached"),
+		Anon:         kb("AnonPages"),
+		Shmem:        kb("Shmem"),
+		Slab:         kb("Slab"),
+		SReclaimable: kb("SReclaimable"),
+		SUnreclaim:   kb("SUnreclaim"),
+		Mapped:       kb("Mapped"),
+		PageTables:   kb("PageTables"),
+		KernelStack:  kb("KernelStack"),
+		Dirty:        kb("Dirty"),
+		Writeback:    kb("Writeback"),
+		SwapTotal:    kb("SwapTotal"),
+		SwapFree:     kb("SwapFree"),
+		SwapCached:   kb("SwapCached"),
+		SwapDevices:  swapDevices(),
+		HugePages: cayman.HugePages{
+			PageSize:      kb("Hugepagesize"),
+			Total:         mi["HugePages_Total"],
+			Free:          mi["HugePages_Free"],
+			Reserved:      mi["HugePages_Rsvd"],
+			Surplus:       mi["HugePages_Surp"],
+			AnonHugePages: kb("AnonHugePages"),
+		},
+		Zswap: cayman.ZswapStats{
+			Enabled: readString(zswapEnabled) == "Y",
+			Pool:    kb("Zswap"),
+			Stored:  kb("Zswapped"),
+		},
+		Zram: zramDevices(),
+	}
+
+	if vm, err := readKeyed(procVmstat, ""); err == nil {
+		d.SwapIns = vm["pswpin"]
+		d.SwapOuts = vm["pswpout"]
+		d.MajFaults = vm["pgmajfault"]
+		d.OOMKills = vm["oom_kill"]
+	}
+	return d, nil
+}
+
+// readKeyed parses files made of "key value" lines, with the key optionally
+// terminated by sep. Trailing units are ignored.
+func readKeyed(name, sep string) (map[string]uint64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		result[strings.TrimSuffix(fields[0], sep)] = v
+	}
+	return result, scanner.Err()
+}
+
+// swapDevices parses /proc/swaps, whose sizes are in kB.
+func swapDevices() []cayman.SwapDevice {
+	result := make([]cayman.SwapDevice, 0)
+	f, err := os.Open(procSwaps)
+	if err != nil {
+		return result
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		size, _ := strconv.ParseUint(fields[2], 10, 64)
+		used, _ := strconv.ParseUint(fields[3], 10, 64)
+		prio, _ := strconv.Atoi(fields[4])
+		result = append(result, cayman.SwapDevice{
+			// spaces in paths are escaped as \040
+			Name:     strings.ReplaceAll(fields[0], `\040`, " "),
+			Type:     fields[1],
+			Size:     size * 1024,
+			Used:     used * 1024,
+			Priority: prio,
+		})
+	}
+	return result
+}
+
+// zramDevices reads the initialized zram devices from sysfs.
+func zramDevices() []cayman.ZramDevice {
+	result := make([]cayman.ZramDevice, 0)
+	dirs, _ := filepath.Glob(filepath.Join(sysBlock, "zram*"))
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		size, _ := strconv.ParseUint(readString(filepath.Join(dir, "disksize")), 10, 64)
+		if size == 0 {
+			continue
+		}
+		z := cayman.ZramDevice{
+			Name:      filepath.Base(dir),
+			Algorithm: selectedAlgorithm(readString(filepath.Join(dir, "comp_algorithm"))),
+			DiskSize:  size,
+		}
+		// orig_data_size compr_data_size mem_used_total mem_limit ...
+		mm := strings.Fields(readString(filepath.Join(dir, "mm_stat")))
+		if len(mm) >= 3 {
+			z.OrigDataSize, _ = strconv.ParseUint(mm[0], 10, 64)
+			z.ComprSize, _ = strconv.ParseUint(mm[1], 10, 64)
+			z.MemUsed, _ = strconv.ParseUint(mm[2], 10, 64)
+		}
+		result = append(result, z)
+	}
+	return result
+}
+
+// selectedAlgorithm picks the bracketed entry from a list such as
+// "lzo [lz4] zstd".
+func selectedAlgorithm(s string) string {
+	for _, alg := range strings.Fields(s) {
+		if strings.HasPrefix(alg, "[") {
+			return strings.Trim(alg, "[]")
+		}
+	}
+	return s
+}
+
+func readString(name string) string {
+	bb, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(bb))
+}
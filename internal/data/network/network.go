@@ -0,0 +1,117 @@
+package network
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+
+	"github.com/shirou/gopsutil/v4/net"
+)
+
+const sysClassNet = "/sys/class/net"
+
+// Collector samples network interface counters. Rates are derived from the
+// difference between successive calls to Collect.
+type Collector struct {
+	mu       sync.Mutex
+	prev     map[string]net.IOCountersStat
+	prevTime time.Time
+}
+
+func NewCollector() *Collector {
+	return &Collector{}
+}
+
+// Collect returns the current state of every network interface. The first
+// call reports zero rates since there is no previous sample to compare with.
+func (c *Collector) Collect(ctx context.Context) ([]cayman.NetInterface, error) {
+	ifaces, err := net.InterfacesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counters, err := net.IOCountersWithContext(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byName := make(map[string]net.IOCountersStat, len(counters))
+	for _, ctr := range counters {
+		byName[ctr.Name] = ctr
+	}
+	elapsed := now.Sub(c.prevTime).Seconds()
+
+	result := make([]cayman.NetInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		ni := cayman.NetInterface{
+			Name:         iface.Name,
+			HardwareAddr: iface.HardwareAddr,
+			Addresses:    make([]string, 0, len(iface.Addrs)),
+			OperState:    operState(iface.Name),
+			Speed:        speed(iface.Name),
+			MTU:          iface.MTU,
+		}
+		for _, addr := range iface.Addrs {
+			ni.Addresses = append(ni.Addresses, addr.Addr)
+		}
+		if ctr, ok := byName[iface.Name]; ok {
+			ni.RxBytes = ctr.BytesRecv
+			ni.TxBytes = ctr.BytesSent
+			ni.RxPackets = ctr.PacketsRecv
+			ni.TxPackets = ctr.PacketsSent
+			ni.RxErrors = ctr.Errin
+			ni.TxErrors = ctr.Errout
+			ni.RxDropped = ctr.Dropin
+			ni.TxDropped = ctr.Dropout
+			if prev, ok := c.prev[iface.Name]; ok && elapsed > 0 {
+				ni.RxBytesPerSec = rate(prev.BytesRecv, ctr.BytesRecv, elapsed)
+				ni.TxBytesPerSec = rate(prev.BytesSent, ctr.BytesSent, elapsed)
+			}
+		}
+		result = append(result, ni)
+	}
+
+	c.prev = byName
+	c.prevTime = now
+	return result, nil
+}
+
+// rate returns the per-second change between two counter values, treating a
+// decrease (counter reset or interface re-creation) as no traffic.
+func rate(prev, cur uint64, seconds float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / seconds
+}
+
+func operState(name string) string {
+	bb, err := os.ReadFile(filepath.Join(sysClassNet, name, "operstate"))
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(bb))
+}
+
+// speed reads the negotiated link speed. Virtual and down interfaces either
+// lack the file or fail to read it, in which case -1 is returned.
+func speed(name string) int {
+	bb, err := os.ReadFile(filepath.Join(sysClassNet, name, "speed"))
+	if err != nil {
+		return -1
+	}
+	s, err := strconv.Atoi(strings.TrimSpace(string(bb)))
+	if err != nil {
+		return -1
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package pressure
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cayman"
+	"cayman/internal/data/cgroup"
+)
+
+const procPressure = "/proc/pressure"
+
+// System returns the host-wide Pressure Stall Information.
+func System() (cayman.PressureInfo, error) {
+	return read(func(resource string) string {
+		return filepath.Join(procPressure, resource)
+	})
+}
+
+// Cgroup returns the Pressure Stall Information of a cgroup. The path is
+// relative to the root of the cgroup v2 hierarchy, e.g.
+// "/system.slice/sshd.service".
+func Cgroup(path string) (cayman.PressureInfo, error) {
+	dir, err := cgroup.Dir(path)
+	if err != nil {
+		return cayman.PressureInfo{}, err
+	}
+	return read(func(resource string) string {
+		return filepath.Join(dir, resource+".pressure")
+	})
+}
+
+func read(file func(resource string) string) (cayman.PressureInfo, error) {
+	var (
+		info cayman.PressureInfo
+		err  error
+	)
+	if info.CPU, err = readFile(file("cpu")); err != nil {
+		return info, err
+	}
+	if info.Memory, err = readFile(file("memory")); err != nil {
+		return info, err
+	}
+	if info.IO, err = readFile(file("io")); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// readFile parses a PSI file of the form
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readFile(name string) (cayman.Pressure, error) {
+	var p cayman.Pressure
+	bb, err := os.ReadFile(name)
+	if err != nil {
+		return p, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(bb)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var stat *cayman.PressureStat
+		switch fields[0] {
+		case "some":
+			stat = &p.Some
+		case "full":
+			stat = &p.Full
+		default:
+			continue
+		}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return p, fmt.Errorf("malformed pressure field %q in %s", field, name)
+			}
+			switch key {
+			case "avg10":
+				stat.Avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				stat.Avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				stat.Avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				stat.Total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return p, fmt.Errorf("parse %s in %s: %w", key, name, err)
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,175 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cayman"
+
+	"golang.org/x/sys/unix"
+)
+
+// I/O scheduling classes, see ioprio_set(2).
+const (
+	ioprioClassNone = iota
+	ioprioClassRealtime
+	ioprioClassBestEffort
+	ioprioClassIdle
+
+	ioprioClassShift = 13
+	ioprioWhoProcess = 1
+)
+
+var ioClasses = []string{"none", "realtime", "best-effort", "idle"}
+
+// ParseSignal accepts a signal name with or without the SIG prefix, in any
+// case, or a signal number.
+func ParseSignal(s string) (unix.Signal, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n <= 0 || n > 64 {
+			return 0, fmt.Errorf("invalid signal number %d", n)
+		}
+		return unix.Signal(n), nil
+	}
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return 0, fmt.Errorf("unknown signal %q", s)
+	}
+	return sig, nil
+}
+
+// Signal sends sig to a process.
+func Signal(pid int32, sig unix.Signal) error {
+	return unix.Kill(int(pid), sig)
+}
+
+// SetNice sets the scheduling priority of a process, from -20 (highest) to
+// 19 (lowest).
+func SetNice(pid int32, nice int) error {
+	if nice < -20 || nice > 19 {
+		return fmt.Errorf("nice value %d out of range -20..19", nice)
+	}
+	return unix.Setpriority(unix.PRIO_PROCESS, int(pid), nice)
+}
+
+// SetIOPriority sets the I/O scheduling class and level of a process. Level
+// ranges from 0 (highest) to 7 and is ignored for the idle and none classes.
+func SetIOPriority(pid int32, class string, level int) error {
+	c := -1
+	for i, name := range ioClasses {
+		if name == class {
+			c = i
+		}
+	}
+	if c < 0 {
+		return fmt.Errorf("unknown io class %q, expected one of %s", class, strings.Join(ioClasses, ", "))
+	}
+	if level < 0 || level > 7 {
+		return fmt.Errorf("io priority level %d out of range 0..7", level)
+	}
+	if c == ioprioClassIdle || c == ioprioClassNone {
+		level = 0
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), uintptr(c<<ioprioClassShift|level))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// SetAffinity restricts a process to the given CPUs.
+func SetAffinity(pid int32, cpus []int) error {
+	if len(cpus) == 0 {
+		return errors.New("at least one cpu is required")
+	}
+	var set unix.CPUSet
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= len(set)*64 {
+			return fmt.Errorf("cpu %d out of range", cpu)
+		}
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(int(pid), &set)
+}
+
+// Scheduling reads back the current scheduling settings of a process.
+func Scheduling(pid int32) (cayman.ProcessScheduling, error) {
+	var sched cayman.ProcessScheduling
+	nice, err := readNice(pid)
+	if err != nil {
+		return sched, err
+	}
+	sched.Nice = nice
+
+	prio, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return sched, errno
+	}
+	class := int(prio >> ioprioClassShift)
+	if class == ioprioClassNone {
+		// without an explicit class the kernel derives best-effort
+		// priority from the nice value
+		sched.IOClass = ioClasses[ioprioClassNone]
+		sched.IOLevel = (nice + 20) / 5
+	} else if class < len(ioClasses) {
+		sched.IOClass = ioClasses[class]
+		sched.IOLevel = int(prio & 0xff)
+	}
+
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(int(pid), &set); err != nil {
+		return sched, err
+	}
+	sched.Affinity = make([]int, 0, set.Count())
+	for cpu := 0; cpu < len(set)*64; cpu++ {
+		if set.IsSet(cpu) {
+			sched.Affinity = append(sched.Affinity, cpu)
+		}
+	}
+	return sched, nil
+}
+
+// Running reports whether a process exists and has not become a zombie.
+func Running(pid int32) bool {
+	bb, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(int(pid)), "stat"))
+	if err != nil {
+		return false
+	}
+	fields, ok := statFields(bb)
+	return ok && fields[0] != "Z" && fields[0] != "X"
+}
+
+// readNice reads the nice value from /proc/<pid>/stat. getpriority(2)
+// returns 20-nice on Linux, which is easy to misread.
+func readNice(pid int32) (int, error) {
+	bb, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(int(pid)), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	fields, ok := statFields(bb)
+	if !ok || len(fields) < 17 {
+		return 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	return strconv.Atoi(fields[16])
+}
+
+// statFields returns the fields of /proc/<pid>/stat following the command
+// name, which may itself contain spaces and parentheses. The first returned
+// field is the state (field 3 in proc(5)).
+func statFields(bb []byte) ([]string, bool) {
+	s := string(bb)
+	i := strings.LastIndexByte(s, ')')
+	if i < 0 {
+		return nil, false
+	}
+	fields := strings.Fields(s[i+1:])
+	return fields, len(fields) > 0
+}
@@ -0,0 +1,185 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+	"cayman/internal/data/cgroup"
+
+	gops "github.com/shirou/gopsutil/v4/process"
+)
+
+// ErrNotFound is returned when a process does not exist.
+var ErrNotFound = errors.New("process not found")
+
+// Collector samples the process table. Processes are tracked between calls
+// to Collect so CPU usage can be computed from the difference in CPU time.
+type Collector struct {
+	mu    sync.Mutex
+	procs map[int32]*gops.Process
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		procs: make(map[int32]*gops.Process),
+	}
+}
+
+// Collect returns every running process. Processes seen for the first time
+// report zero CPU usage.
+func (c *Collector) Collect(ctx context.Context) ([]cayman.ProcessInfo, error) {
+	pids, err := gops.PidsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[int32]*gops.Process, len(pids))
+	result := make([]cayman.ProcessInfo, 0, len(pids))
+	for _, pid := range pids {
+		p, ok := c.procs[pid]
+		if !ok {
+			if p, err = gops.NewProcessWithContext(ctx, pid); err != nil {
+				continue
+			}
+		}
+		pi, err := info(ctx, p)
+		if err != nil {
+			// exited between listing and reading
+			continue
+		}
+		seen[pid] = p
+		result = append(result, pi)
+	}
+	c.procs = seen
+	return result, nil
+}
+
+// Detail returns the expanded view of a process. The environment is only
+// read when withEnv is set.
+func (c *Collector) Detail(ctx context.Context, pid int32, withEnv bool) (*cayman.ProcessDetail, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.procs[pid]
+	if !ok {
+		var err error
+		if p, err = gops.NewProcessWithContext(ctx, pid); err != nil {
+			return nil, ErrNotFound
+		}
+	}
+	pi, err := info(ctx, p)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	detail := &cayman.ProcessDetail{
+		Process:   pi,
+		OpenFiles: make([]string, 0),
+		Children:  make([]cayman.ProcessInfo, 0),
+	}
+	detail.Executable, _ = p.ExeWithContext(ctx)
+	detail.Cwd, _ = p.CwdWithContext(ctx)
+	if files, err := p.OpenFilesWithContext(ctx); err == nil {
+		for _, f := range files {
+			detail.OpenFiles = append(detail.OpenFiles, f.Path)
+		}
+	}
+	if withEnv {
+		detail.Environment, _ = p.EnvironWithContext(ctx)
+	}
+	detail.Limits, _ = limits(pid)
+	if children, err := p.ChildrenWithContext(ctx); err == nil {
+		for _, child := range children {
+			if tracked, ok := c.procs[child.Pid]; ok {
+				child = tracked
+			}
+			if ci, err := info(ctx, child); err == nil {
+				detail.Children = append(detail.Children, ci)
+			}
+		}
+	}
+	return detail, nil
+}
+
+// info reads the list view of a process. Only a failure to read the name is
+// fatal; other fields are left empty when the caller lacks permission.
+func info(ctx context.Context, p *gops.Process) (cayman.ProcessInfo, error) {
+	name, err := p.NameWithContext(ctx)
+	if err != nil {
+		return cayman.ProcessInfo{}, err
+	}
+	pi := cayman.ProcessInfo{
+		PID:  p.Pid,
+		Name: name,
+	}
+	pi.PPID, _ = p.PpidWithContext(ctx)
+	pi.User, _ = p.UsernameWithContext(ctx)
+	pi.Command, _ = p.CmdlineWithContext(ctx)
+	if pi.Command == "" {
+		// kernel threads have no command line
+		pi.Command = "[" + name + "]"
+	}
+	pi.CPUPercent, _ = p.PercentWithContext(ctx, 0)
+	if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+		pi.RSS = mem.RSS
+	}
+	pi.Threads, _ = p.NumThreadsWithContext(ctx)
+	if status, err := p.StatusWithContext(ctx); err == nil && len(status) > 0 {
+		pi.State = status[0]
+	}
+	if created, err := p.CreateTimeWithContext(ctx); err == nil {
+		pi.StartTime = time.UnixMilli(created)
+	}
+	if path, err := cgroup.PIDPath(int(p.Pid)); err == nil {
+		pi.Cgroup = path
+		pi.Unit = cgroup.Unit(path)
+	}
+	return pi, nil
+}
+
+// limits parses /proc/<pid>/limits, whose columns are aligned under the
+// header line.
+func limits(pid int32) ([]cayman.ProcessLimit, error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(int(pid)), "limits"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := make([]cayman.ProcessLimit, 0)
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return result, scanner.Err()
+	}
+	header := scanner.Text()
+	soft := strings.Index(header, "Soft Limit")
+	hard := strings.Index(header, "Hard Limit")
+	units := strings.Index(header, "Units")
+	if soft < 0 || hard < 0 || units < 0 {
+		return result, errors.New("unexpected limits header")
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < units {
+			continue
+		}
+		result = append(result, cayman.ProcessLimit{
+			Resource: strings.TrimSpace(line[:soft]),
+			Soft:     strings.TrimSpace(line[soft:hard]),
+			Hard:     strings.TrimSpace(line[hard:units]),
+			Units:    strings.TrimSpace(line[units:]),
+		})
+	}
+	return result, scanner.Err()
+}
@@ -0,0 +1,203 @@
+package sensors
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cayman"
+)
+
+const (
+	sysHwmon       = "/sys/class/hwmon"
+	sysThermal     = "/sys/class/thermal"
+	sysPowerSupply = "/sys/class/power_supply"
+)
+
+// inputFile matches hwmon channel inputs such as temp1_input or power2_average.
+var inputFile = regexp.MustCompile(`^(temp|fan|power)(\d+)_(input|average)$`)
+
+// hwmon channel types and the divisor converting their raw sysfs value to
+// the unit documented on cayman.SensorKind.
+var channels = map[string]struct {
+	kind    cayman.SensorKind
+	divisor float64
+}{
+	"temp":  {cayman.SensorKindTemperature, 1000},  // millidegree Celsius
+	"fan":   {cayman.SensorKindFan, 1},             // RPM
+	"power": {cayman.SensorKindPower, 1000 * 1000}, // microwatt
+}
+
+// Read returns all hwmon sensors, thermal zones not already exposed through
+// hwmon, and power supplies. Hosts without any of these report empty lists.
+func Read() cayman.SensorsInfo {
+	info := cayman.SensorsInfo{
+		Sensors:       hwmon(),
+		PowerSupplies: powerSupplies(),
+	}
+	info.Sensors = append(info.Sensors, thermalZones()...)
+	return info
+}
+
+// Critical reports whether a sensor is in a critical state: a temperature or
+// power at or above its critical threshold, or a fan in alarm or below its
+// minimum speed.
+func Critical(s cayman.Sensor) bool {
+	if s.Kind == cayman.SensorKindFan {
+		return s.Alarm || (s.Low > 0 && s.Value < s.Low)
+	}
+	return s.Critical > 0 && s.Value >= s.Critical
+}
+
+func hwmon() []cayman.Sensor {
+	result := make([]cayman.Sensor, 0)
+	dirs, _ := filepath.Glob(filepath.Join(sysHwmon, "hwmon*"))
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		chip := readString(filepath.Join(dir, "name"))
+		if chip == "" {
+			chip = filepath.Base(dir)
+		}
+		// the device path tells identical chips apart, the hwmon number
+		// alone may change with the probe order
+		device, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+		if err != nil {
+			device = dir
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			m := inputFile.FindStringSubmatch(entry.Name())
+			if m == nil {
+				continue
+			}
+			prefix := m[1] + m[2]
+			// power channels may expose both input and average
+			if seen[prefix] {
+				continue
+			}
+			value, ok := readFloat(filepath.Join(dir, entry.Name()))
+			if !ok {
+				continue
+			}
+			seen[prefix] = true
+			ch := channels[m[1]]
+			label := readString(filepath.Join(dir, prefix+"_label"))
+			if label == "" {
+				label = prefix
+			}
+			s := cayman.Sensor{
+				ID:    device + "/" + prefix,
+				Chip:  chip,
+				Label: label,
+				Kind:  ch.kind,
+				Value: value / ch.divisor,
+			}
+			if v, ok := readFloat(filepath.Join(dir, prefix+"_max")); ok {
+				s.High = v / ch.divisor
+			}
+			if alarm, ok := readFloat(filepath.Join(dir, prefix+"_alarm")); ok {
+				s.Alarm = alarm != 0
+			}
+			if ch.kind == cayman.SensorKindFan {
+				// fans have a minimum speed rather than a critical one
+				if v, ok := readFloat(filepath.Join(dir, prefix+"_min")); ok {
+					s.Low = v / ch.divisor
+				}
+			} else {
+				for _, name := range []string{"_crit", "_cap"} {
+					if v, ok := readFloat(filepath.Join(dir, prefix+name)); ok {
+						s.Critical = v / ch.divisor
+						break
+					}
+				}
+			}
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func thermalZones() []cayman.Sensor {
+	result := make([]cayman.Sensor, 0)
+	zones, _ := filepath.Glob(filepath.Join(sysThermal, "thermal_zone*"))
+	sort.Strings(zones)
+	for _, zone := range zones {
+		// zones registered with hwmon were already reported by hwmon()
+		if linked, _ := filepath.Glob(filepath.Join(zone, "hwmon*")); len(linked) > 0 {
+			continue
+		}
+		temp, ok := readFloat(filepath.Join(zone, "temp"))
+		if !ok {
+			continue
+		}
+		s := cayman.Sensor{
+			ID:    zone,
+			Chip:  filepath.Base(zone),
+			Label: readString(filepath.Join(zone, "type")),
+			Kind:  cayman.SensorKindTemperature,
+			Value: temp / 1000,
+		}
+		trips, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, trip := range trips {
+			v, ok := readFloat(strings.TrimSuffix(trip, "_type") + "_temp")
+			if !ok {
+				continue
+			}
+			switch readString(trip) {
+			case "hot":
+				s.High = v / 1000
+			case "critical":
+				s.Critical = v / 1000
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func powerSupplies() []cayman.PowerSupply {
+	result := make([]cayman.PowerSupply, 0)
+	dirs, _ := filepath.Glob(filepath.Join(sysPowerSupply, "*"))
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		ps := cayman.PowerSupply{
+			Name:     filepath.Base(dir),
+			Type:     readString(filepath.Join(dir, "type")),
+			Status:   readString(filepath.Join(dir, "status")),
+			Online:   readString(filepath.Join(dir, "online")) == "1",
+			Capacity: -1,
+		}
+		if v, ok := readFloat(filepath.Join(dir, "capacity")); ok {
+			ps.Capacity = int(v)
+		}
+		result = append(result, ps)
+	}
+	return result
+}
+
+func readString(name string) string {
+	bb, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(bb))
+}
+
+func readFloat(name string) (float64, bool) {
+	s := readString(name)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
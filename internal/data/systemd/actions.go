@@ -0,0 +1,91 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+
+	"cayman"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// JobActions are the actions that queue a job and complete asynchronously.
+var JobActions = []string{"start", "stop", "restart", "reload"}
+
+// FileActions are the actions that change unit file symlinks.
+var FileActions = []string{"enable", "disable", "mask", "unmask"}
+
+// RunJob queues a start, stop, restart or reload job for a unit and waits
+// for it to finish. The job result is returned along with an error when it
+// is anything other than "done".
+func (m *Manager) RunJob(ctx context.Context, action, name string) (string, error) {
+	var queue func(context.Context, string, string, chan<- string) (int, error)
+	switch action {
+	case "start":
+		queue = m.conn.StartUnitContext
+	case "stop":
+		queue = m.conn.StopUnitContext
+	case "restart":
+		queue = m.conn.RestartUnitContext
+	case "reload":
+		queue = m.conn.ReloadUnitContext
+	default:
+		return "", fmt.Errorf("unknown job action %q", action)
+	}
+
+	done := make(chan string, 1)
+	if _, err := queue(ctx, name, "replace", done); err != nil {
+		return "", err
+	}
+	select {
+	case result := <-done:
+		if result != "done" {
+			return result, fmt.Errorf("%s job for %s finished with result %q", action, name, result)
+		}
+		return result, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// ChangeUnitFile enables, disables, masks or unmasks a unit persistently and
+// reloads the manager configuration, as systemctl does.
+func (m *Manager) ChangeUnitFile(ctx context.Context, action, name string) ([]cayman.UnitFileChange, error) {
+	var (
+		changes []cayman.UnitFileChange
+		err     error
+	)
+	files := []string{name}
+	switch action {
+	case "enable":
+		var cc []dbus.EnableUnitFileChange
+		_, cc, err = m.conn.EnableUnitFilesContext(ctx, files, false, false)
+		for _, c := range cc {
+			changes = append(changes, cayman.UnitFileChange(c))
+		}
+	case "disable":
+		var cc []dbus.DisableUnitFileChange
+		cc, err = m.conn.DisableUnitFilesContext(ctx, files, false)
+		for _, c := range cc {
+			changes = append(changes, cayman.UnitFileChange(c))
+		}
+	case "mask":
+		var cc []dbus.MaskUnitFileChange
+		cc, err = m.conn.MaskUnitFilesContext(ctx, files, false, false)
+		for _, c := range cc {
+			changes = append(changes, cayman.UnitFileChange(c))
+		}
+	case "unmask":
+		var cc []dbus.UnmaskUnitFileChange
+		cc, err = m.conn.UnmaskUnitFilesContext(ctx, files, false)
+		for _, c := range cc {
+			changes = append(changes, cayman.UnitFileChange(c))
+		}
+	default:
+		return nil, fmt.Errorf("unknown unit file action %q", action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return changes, m.conn.ReloadContext(ctx)
+}
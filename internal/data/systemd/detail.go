@@ -0,0 +1,175 @@
+package systemd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"cayman"
+	"cayman/internal/redact"
+)
+
+// systemDropInDir is where drop-ins for system units are placed. Drop-ins
+// for user units go to $XDG_CONFIG_HOME/systemd/user.
+const systemDropInDir = "/etc/systemd/system"
+
+// dependencyProperties are the unit properties listing other units, forward
+// dependencies first and reverse dependencies second.
+var dependencyProperties = []string{
+	"Requires", "Requisite", "Wants", "BindsTo", "PartOf", "Upholds", "Conflicts", "Before", "After",
+	"RequiredBy", "RequisiteOf", "WantedBy", "BoundBy", "ConsistsOf", "UpheldBy", "ConflictedBy",
+}
+
+var dropInName = regexp.MustCompile(`^[A-Za-z0-9_.@-]+$`)
+
+// Detail returns all properties, dependencies and files of a unit. With
+// redactSecrets, secret-looking values of the environment, the command lines
+// and the files are masked.
+func (m *Manager) Detail(ctx context.Context, name string, redactSecrets bool) (*cayman.UnitDetail, error) {
+	props, err := m.conn.GetAllPropertiesContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	detail := &cayman.UnitDetail{
+		Unit:         m.unitFromProperties(name, props),
+		Properties:   props,
+		Dependencies: make(map[string][]string),
+		Files:        make([]cayman.UnitFile, 0),
+	}
+	for _, dep := range dependencyProperties {
+		if units, ok := props[dep].([]string); ok && len(units) > 0 {
+			detail.Dependencies[dep] = units
+		}
+	}
+
+	paths := make([]string, 0)
+	if fragment, ok := props["FragmentPath"].(string); ok && fragment != "" {
+		paths = append(paths, fragment)
+	}
+	if dropins, ok := props["DropInPaths"].([]string); ok {
+		paths = append(paths, dropins...)
+	}
+	for _, p := range paths {
+		bb, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		content := string(bb)
+		if redactSecrets {
+			content = redactUnitFile(content)
+		}
+		detail.Files = append(detail.Files, cayman.UnitFile{Path: p, Content: content})
+	}
+	if redactSecrets {
+		redactProperties(props)
+	}
+	return detail, nil
+}
+
+// redactProperties masks the secret-looking values of the environment and
+// the command lines of a unit's properties.
+func redactProperties(props map[string]any) {
+	for key, value := range props {
+		switch v := value.(type) {
+		case []string:
+			// Environment, and the pass and unset variants
+			if strings.HasPrefix(key, "Environment") {
+				props[key] = redact.Env(v)
+			}
+		case [][]any:
+			// ExecStart and the like are lists of (path, argv, ...),
+			// SetCredential lists of (id, data)
+			exec, credential := strings.HasPrefix(key, "Exec"), strings.HasPrefix(key, "SetCredential")
+			if !exec && !credential {
+				continue
+			}
+			entries := make([][]any, len(v))
+			for i, entry := range v {
+				entries[i] = slices.Clone(entry)
+				if len(entry) < 2 {
+					continue
+				}
+				if credential {
+					entries[i][1] = redact.Mask
+				} else if argv, ok := entry[1].([]string); ok {
+					entries[i][1] = redact.Args(argv)
+				}
+			}
+			props[key] = entries
+		}
+	}
+}
+
+// redactUnitFile masks the secret-looking values set in a unit file,
+// leaving comments and section headers as they are.
+func redactUnitFile(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' || trimmed[0] == '[' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(key), "SetCredential") {
+			// SetCredential=id:data
+			id, _, _ := strings.Cut(value, ":")
+			lines[i] = key + "=" + id + ":" + redact.Mask
+			continue
+		}
+		lines[i] = key + "=" + redact.Text(value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WriteDropIn writes content to the drop-in <unit>.d/<name>.conf and reloads
+// the manager so it takes effect. Empty content removes the drop-in. The
+// path of the drop-in is returned.
+func (m *Manager) WriteDropIn(ctx context.Context, unit, name, content string) (string, error) {
+	if unit == "" || unit == "." || unit == ".." || strings.ContainsAny(unit, "/\x00") {
+		return "", errors.New("invalid unit name")
+	}
+	name = strings.TrimSuffix(name, ".conf")
+	if !dropInName.MatchString(name) {
+		return "", errors.New("invalid drop-in name")
+	}
+	base := systemDropInDir
+	if m.scope == cayman.UnitScopeUser {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(config, "systemd", "user")
+	}
+	dir := filepath.Join(base, unit+".d")
+	file := filepath.Join(dir, name+".conf")
+
+	if content == "" {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return file, err
+		}
+	} else {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return file, err
+		}
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		// write then rename so systemd never reads a partial file
+		tmp := file + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+			return file, err
+		}
+		if err := os.Rename(tmp, file); err != nil {
+			os.Remove(tmp)
+			return file, err
+		}
+	}
+	return file, m.conn.ReloadContext(ctx)
+}
@@ -0,0 +1,193 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"cayman"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// Manager is a long lived connection to the systemd manager, used where a
+// connection per call would be too expensive or signals must be received.
+type Manager struct {
+	conn  *dbus.Conn
+	scope cayman.UnitScope
+}
+
+// NewManager connects to the system or user manager. Callers should call
+// Close when done with it.
+func NewManager(ctx context.Context, scope cayman.UnitScope) (*Manager, error) {
+	conn, err := connect(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{conn: conn, scope: scope}, nil
+}
+
+func (m *Manager) Close() {
+	m.conn.Close()
+}
+
+// Scope returns the manager this connection talks to.
+func (m *Manager) Scope() cayman.UnitScope {
+	return m.scope
+}
+
+// ParseScope parses a scope name, defaulting to the system manager when
+// empty.
+func ParseScope(s string) (cayman.UnitScope, error) {
+	switch scope := cayman.UnitScope(s); scope {
+	case "":
+		return cayman.UnitScopeSystem, nil
+	case cayman.UnitScopeSystem, cayman.UnitScopeUser:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown scope %q, expected system or user", s)
+	}
+}
+
+// connect opens a connection to the system manager, or to the user manager
+// over the session bus.
+func connect(ctx context.Context, scope cayman.UnitScope) (*dbus.Conn, error) {
+	switch scope {
+	case cayman.UnitScopeSystem:
+		return dbus.NewWithContext(ctx)
+	case cayman.UnitScopeUser:
+		return dbus.NewUserConnectionContext(ctx)
+	default:
+		return nil, fmt.Errorf("unknown scope %q", scope)
+	}
+}
+
+// ListUnits returns all units currently loaded by the manager.
+func (m *Manager) ListUnits(ctx context.Context) ([]cayman.Unit, error) {
+	uu, err := m.conn.ListUnitsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the enablement state is only available from the unit files
+	enabled := make(map[string]string)
+	if files, err := m.conn.ListUnitFilesContext(ctx); err == nil {
+		for _, f := range files {
+			enabled[path.Base(f.Path)] = f.Type
+		}
+	}
+
+	result := make([]cayman.Unit, 0, len(uu))
+	for _, u := range uu {
+		unit := cayman.Unit{
+			Name:         u.Name,
+			Scope:        m.scope,
+			Description:  u.Description,
+			Type:         unitType(u.Name),
+			LoadState:    u.LoadState,
+			ActiveState:  u.ActiveState,
+			SubState:     u.SubState,
+			EnabledState: enabled[u.Name],
+		}
+		if unit.Type == "service" && u.ActiveState != "inactive" {
+			if prop, err := m.conn.GetServicePropertyContext(ctx, u.Name, "MainPID"); err == nil {
+				unit.MainPID, _ = prop.Value.Value().(uint32)
+			}
+		}
+		result = append(result, unit)
+	}
+	return result, nil
+}
+
+// Unit returns the current state of a single unit.
+func (m *Manager) Unit(ctx context.Context, name string) (cayman.Unit, error) {
+	props, err := m.conn.GetAllPropertiesContext(ctx, name)
+	if err != nil {
+		return cayman.Unit{}, err
+	}
+	return m.unitFromProperties(name, props), nil
+}
+
+// unitFromProperties builds the summary of a unit from its D-Bus properties.
+func (m *Manager) unitFromProperties(name string, props map[string]any) cayman.Unit {
+	str := func(key string) string {
+		s, _ := props[key].(string)
+		return s
+	}
+	unit := cayman.Unit{
+		Name:         name,
+		Scope:        m.scope,
+		Description:  str("Description"),
+		Type:         unitType(name),
+		LoadState:    str("LoadState"),
+		ActiveState:  str("ActiveState"),
+		SubState:     str("SubState"),
+		EnabledState: str("UnitFileState"),
+	}
+	unit.MainPID, _ = props["MainPID"].(uint32)
+	return unit
+}
+
+// Watch subscribes to unit property changes and sends the name of each
+// changed unit on the returned channel until ctx is done. Changes are
+// delivered from D-Bus PropertiesChanged signals, so no polling is involved.
+func (m *Manager) Watch(ctx context.Context) (<-chan string, <-chan error, error) {
+	if err := m.conn.Subscribe(); err != nil {
+		return nil, nil, err
+	}
+	updates := make(chan *dbus.PropertiesUpdate, 256)
+	errs := make(chan error, 16)
+	m.conn.SetPropertiesSubscriber(updates, errs)
+
+	names := make(chan string, 256)
+	go func() {
+		defer close(names)
+		defer m.conn.SetPropertiesSubscriber(nil, nil)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u := <-updates:
+				select {
+				case names <- u.UnitName:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return names, errs, nil
+}
+
+// Failure describes how the main process of a unit ended.
+type Failure struct {
+	Result     string // exit-code, signal, core-dump, timeout, ...
+	ExitCode   string // exited, killed or dumped
+	ExitStatus int32  // exit status, or signal number when killed or dumped
+}
+
+// FailureCause reads the result and main process exit of a failed unit.
+func (m *Manager) FailureCause(ctx context.Context, name string) (Failure, error) {
+	props, err := m.conn.GetAllPropertiesContext(ctx, name)
+	if err != nil {
+		return Failure{}, err
+	}
+	f := Failure{}
+	f.Result, _ = props["Result"].(string)
+	f.ExitStatus, _ = props["ExecMainStatus"].(int32)
+	// ExecMainCode is a CLD_* code from waitid(2)
+	switch code, _ := props["ExecMainCode"].(int32); code {
+	case 1:
+		f.ExitCode = "exited"
+	case 2:
+		f.ExitCode = "killed"
+	case 3:
+		f.ExitCode = "dumped"
+	}
+	return f, nil
+}
+
+// unitType returns the unit type from its name suffix, e.g. "service".
+func unitType(name string) string {
+	return strings.TrimPrefix(path.Ext(name), ".")
+}
@@ -2,29 +2,20 @@ package systemd
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
+	"path"
+	"strings"
 
-	"github.com/coreos/go-systemd/v22/dbus"
+	"cayman"
 )
 
-func UnitOverview(ctx context.Context) (failed, active int, err error) {
-	dbusConn, err := dbus.NewWithContext(ctx)
+// UnitOverview counts the failed and active units of the manager.
+func (m *Manager) UnitOverview(ctx context.Context) (failed, active int, err error) {
+	uu, err := m.conn.ListUnitsContext(ctx)
 	if err != nil {
-		slog.Error("Failed to connect to systemd", "err", err)
-		return
+		return 0, 0, err
 	}
-	defer dbusConn.Close()
-
-	uu, err := dbusConn.ListUnitsContext(ctx)
-	if err != nil {
-		slog.Error("Failed to list units", "err", err)
-		return
-	}
-	failed = 0
-	active = 0
-
 	for _, u := range uu {
-
 		if u.ActiveState == "failed" || u.SubState == "failed" {
 			failed++
 		}
@@ -32,5 +23,31 @@ func UnitOverview(ctx context.Context) (failed, active int, err error) {
 			active++
 		}
 	}
-	return
+	return failed, active, nil
+}
+
+// ControlGroup returns the cgroup path of a unit relative to the root of the
+// cgroup hierarchy.
+func ControlGroup(ctx context.Context, scope cayman.UnitScope, unit string) (string, error) {
+	dbusConn, err := connect(ctx, scope)
+	if err != nil {
+		return "", err
+	}
+	defer dbusConn.Close()
+
+	// ControlGroup lives on the type specific interface, e.g. Service or Scope
+	ext := path.Ext(unit)
+	if len(ext) < 2 {
+		return "", fmt.Errorf("unit %q has no type suffix", unit)
+	}
+	unitType := strings.ToUpper(ext[1:2]) + ext[2:]
+	prop, err := dbusConn.GetUnitTypePropertyContext(ctx, unit, unitType, "ControlGroup")
+	if err != nil {
+		return "", err
+	}
+	cgroup, ok := prop.Value.Value().(string)
+	if !ok || cgroup == "" {
+		return "", fmt.Errorf("unit %q has no control group", unit)
+	}
+	return cgroup, nil
 }
@@ -0,0 +1,73 @@
+package systemd
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"cayman"
+
+	"golang.org/x/sys/unix"
+)
+
+// ListTimers returns all loaded timer units with the next and last trigger
+// times and the result of the last run of the unit they activate.
+func (m *Manager) ListTimers(ctx context.Context) ([]cayman.Timer, error) {
+	uu, err := m.conn.ListUnitsByPatternsContext(ctx, nil, []string{"*.timer"})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]cayman.Timer, 0, len(uu))
+	for _, u := range uu {
+		props, err := m.conn.GetUnitTypePropertiesContext(ctx, u.Name, "Timer")
+		if err != nil {
+			continue
+		}
+		t := cayman.Timer{
+			Name:                u.Name,
+			Scope:               m.scope,
+			Description:         u.Description,
+			ActiveState:         u.ActiveState,
+			NextElapseRealtime:  realtime(props["NextElapseUSecRealtime"]),
+			NextElapseMonotonic: monotonic(props["NextElapseUSecMonotonic"]),
+			LastTrigger:         realtime(props["LastTriggerUSec"]),
+		}
+		t.Unit, _ = props["Unit"].(string)
+		if t.Unit != "" {
+			if unit, err := m.conn.GetAllPropertiesContext(ctx, t.Unit); err == nil {
+				t.UnitResult, _ = unit["Result"].(string)
+				t.UnitActiveState, _ = unit["ActiveState"].(string)
+				t.UnitExitStatus, _ = unit["ExecMainStatus"].(int32)
+			}
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// realtime converts a CLOCK_REALTIME timestamp in microseconds, where zero
+// and the maximum value mean unset.
+func realtime(v any) *time.Time {
+	usec, ok := v.(uint64)
+	if !ok || usec == 0 || usec == math.MaxUint64 {
+		return nil
+	}
+	t := time.UnixMicro(int64(usec))
+	return &t
+}
+
+// monotonic converts a CLOCK_MONOTONIC timestamp in microseconds to wall
+// clock time.
+func monotonic(v any) *time.Time {
+	usec, ok := v.(uint64)
+	if !ok || usec == 0 || usec == math.MaxUint64 {
+		return nil
+	}
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return nil
+	}
+	now := time.Now()
+	t := now.Add(time.Duration(int64(usec)-ts.Nano()/1000) * time.Microsecond)
+	return &t
+}
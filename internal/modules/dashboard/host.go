@@ -3,14 +3,27 @@ package dashboard
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"cayman"
+	"cayman/internal/data/cgroup"
+	"cayman/internal/data/disk"
 	"cayman/internal/data/hardware"
+	"cayman/internal/data/memory"
+	"cayman/internal/data/network"
+	"cayman/internal/data/pressure"
+	"cayman/internal/data/sensors"
 	"cayman/internal/data/system"
 	"cayman/internal/data/systemd"
+	"cayman/internal/ringbuffer"
 	syssse "cayman/internal/sse"
+	sysevents "cayman/internal/system"
 
 	"github.com/elastic/go-sysinfo/types"
 	"github.com/labstack/echo/v4"
@@ -23,6 +36,10 @@ var (
 	topicHost  = "dashboard"
 )
 
+// historySize is the number of samples kept in the metrics history, one hour
+// at the poll interval.
+const historySize = 1200
+
 func init() {
 	dashModule = &DashboardModule{}
 	cayman.RegisterModule(dashModule)
@@ -31,7 +48,19 @@ func init() {
 type DashboardModule struct {
 	ctx        context.Context
 	sseHandler *sse.Server
+	mu         sync.RWMutex
 	info       *cayman.HostState
+	netStats   *network.Collector
+	diskStats  *disk.Collector
+	history    *ringbuffer.RingBuffer[cayman.MetricsSample]
+	// critical holds the IDs of the sensors currently in a critical state
+	// so a warning is raised only when one enters it
+	critical map[string]bool
+	// hasSystemd is set when the system manager was reachable at startup;
+	// systemd is the connection used to count units, only used from Poll
+	// once registered and made again after an error.
+	hasSystemd bool
+	systemd    *systemd.Manager
 }
 
 func (h *DashboardModule) ShouldEnable() bool {
@@ -49,19 +78,26 @@ func (h *DashboardModule) Name() string {
 func (h *DashboardModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Group) {
 	h.ctx = ctx
 	h.sseHandler = syssse.NewSSE(topicHost)
+	h.netStats = network.NewCollector()
+	h.diskStats = disk.NewCollector()
+	h.history = ringbuffer.New[cayman.MetricsSample](historySize)
+	h.critical = make(map[string]bool)
 	routeGroup := parentRoute.Group("/dashboard")
-	go h.Poll()
 	routeGroup.GET("/events", echo.WrapHandler(h.sseHandler))
 	routeGroup.GET("/current", h.hostInfoHandler)
+	routeGroup.GET("/history", h.historyHandler)
+	routeGroup.GET("/pressure/cgroup", h.cgroupPressureHandler)
 	cpustat, err := hardware.Info()
 	if err != nil {
 		slog.Error("failed to get cpu info", "error", err)
 	}
 	slog.Info("CPU Info", "count", len(cpustat))
-	failed, active, err := systemd.UnitOverview(ctx)
-	if err != nil {
-		slog.Error("failed to get systemd unit status", "error", err)
+	if manager, err := systemd.NewManager(ctx, cayman.UnitScopeSystem); err != nil {
+		slog.Info("systemd is not available, unit counts are disabled", "error", err)
+	} else {
+		h.hasSystemd, h.systemd = true, manager
 	}
+	units, _ := h.unitStatus()
 	sysinfo, err := system.HostInfo()
 	if err != nil {
 		slog.Error("failed to get host info", "error", err)
@@ -86,19 +122,39 @@ func (h *DashboardModule) RegisterRoutes(ctx context.Context, parentRoute *echo.
 	if err != nil {
 		slog.Error("failed to get FQDN", "error", err)
 	}
+	ifaces, err := h.netStats.Collect(ctx)
+	if err != nil {
+		slog.Error("failed to get network interfaces", "error", err)
+	}
+	diskio, err := h.diskStats.Collect(ctx)
+	if err != nil {
+		slog.Error("failed to get disk io", "error", err)
+	}
+	psi, err := pressure.System()
+	if err != nil {
+		slog.Error("failed to get pressure stall information", "error", err)
+	}
+	memdetail, err := memory.Detail()
+	if err != nil {
+		slog.Error("failed to get memory detail", "error", err)
+	}
 	hi := &cayman.HostState{
-		FQDN:     domain,
-		CPUCount: len(cpustat),
-		UnitStatus: cayman.UnitStatus{
-			FailedCount: failed,
-			ActiveCount: active,
-		},
+		Hostname:   sysinfo.Info().Hostname,
+		FQDN:       domain,
+		CPUCount:   len(cpustat),
+		UnitStatus: units,
 
 		Load:       tmpLoad,
 		HostInfo:   sysinfo.Info(),
 		MemoryInfo: *mem,
+		Network:    ifaces,
+		DiskIO:     diskio,
+		Pressure:   psi,
+		Sensors:    sensors.Read(),
+		Memory:     memdetail,
 	}
 	h.info = hi
+	go h.Poll()
 }
 
 func (h *DashboardModule) Poll() {
@@ -110,6 +166,12 @@ func (h *DashboardModule) Poll() {
 		case <-ticker.C:
 			h.usage()
 			h.stats()
+			h.network()
+			h.diskio()
+			h.pressure()
+			h.sensors()
+			h.memory()
+			h.record()
 		case <-h.ctx.Done():
 			return
 		}
@@ -121,6 +183,9 @@ func (h *DashboardModule) usage() {
 	if err != nil {
 		slog.Error("failed to get cpu usage", "error", err)
 	}
+	h.mu.Lock()
+	h.info.CPU = int(usage)
+	h.mu.Unlock()
 
 	e := &sse.Message{
 		Type: sse.Type("cpu"),
@@ -138,12 +203,10 @@ func (h *DashboardModule) stats() {
 	if err != nil {
 		slog.Error("failed to get host info", "error", err)
 	}
-	h.info.HostInfo = sysinfo.Info()
 	mem, err := sysinfo.Memory()
 	if err != nil {
 		slog.Error("failed to get memory info", "error", err)
 	}
-	h.info.MemoryInfo = *mem
 	var tmpLoad cayman.Load
 	if loadaverage, ok := sysinfo.(types.LoadAverage); ok {
 		loadavg, err := loadaverage.LoadAverage()
@@ -156,7 +219,15 @@ func (h *DashboardModule) stats() {
 			Load15: loadavg.Fifteen,
 		}
 	}
+	units, unitsOK := h.unitStatus()
+	h.mu.Lock()
+	h.info.HostInfo = sysinfo.Info()
+	h.info.MemoryInfo = *mem
 	h.info.Load = tmpLoad
+	if unitsOK {
+		h.info.UnitStatus = units
+	}
+	h.mu.Unlock()
 	e := &sse.Message{
 		Type: sse.Type("mem"),
 	}
@@ -178,8 +249,242 @@ func (h *DashboardModule) stats() {
 	e.AppendData(string(bb))
 
 	_ = h.sseHandler.Publish(e, topicHost)
+
+	if !unitsOK {
+		return
+	}
+	e = &sse.Message{
+		Type: sse.Type("units"),
+	}
+	bb, err = json.Marshal(units)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+// unitStatus counts the failed and active system units. It reports false
+// without systemd or when the count failed, in which case the connection is
+// made again on the next call since it does not recover once dropped.
+func (h *DashboardModule) unitStatus() (cayman.UnitStatus, bool) {
+	if !h.hasSystemd {
+		return cayman.UnitStatus{}, false
+	}
+	if h.systemd == nil {
+		manager, err := systemd.NewManager(h.ctx, cayman.UnitScopeSystem)
+		if err != nil {
+			slog.Debug("failed to reconnect to systemd", "error", err)
+			return cayman.UnitStatus{}, false
+		}
+		h.systemd = manager
+	}
+	failed, active, err := h.systemd.UnitOverview(h.ctx)
+	if err != nil {
+		slog.Error("failed to get systemd unit status", "error", err)
+		h.systemd.Close()
+		h.systemd = nil
+		return cayman.UnitStatus{}, false
+	}
+	return cayman.UnitStatus{
+		FailedCount: failed,
+		ActiveCount: active,
+	}, true
+}
+
+func (h *DashboardModule) network() {
+	ifaces, err := h.netStats.Collect(h.ctx)
+	if err != nil {
+		slog.Error("failed to get network interfaces", "error", err)
+		return
+	}
+	h.mu.Lock()
+	h.info.Network = ifaces
+	h.mu.Unlock()
+
+	e := &sse.Message{
+		Type: sse.Type("net"),
+	}
+	bb, err := json.Marshal(ifaces)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+func (h *DashboardModule) diskio() {
+	diskio, err := h.diskStats.Collect(h.ctx)
+	if err != nil {
+		slog.Error("failed to get disk io", "error", err)
+		return
+	}
+	h.mu.Lock()
+	h.info.DiskIO = diskio
+	h.mu.Unlock()
+
+	e := &sse.Message{
+		Type: sse.Type("disk"),
+	}
+	bb, err := json.Marshal(diskio)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+func (h *DashboardModule) pressure() {
+	psi, err := pressure.System()
+	if err != nil {
+		slog.Error("failed to get pressure stall information", "error", err)
+		return
+	}
+	h.mu.Lock()
+	h.info.Pressure = psi
+	h.mu.Unlock()
+
+	e := &sse.Message{
+		Type: sse.Type("pressure"),
+	}
+	bb, err := json.Marshal(psi)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+func (h *DashboardModule) sensors() {
+	info := sensors.Read()
+	h.mu.Lock()
+	h.info.Sensors = info
+	h.mu.Unlock()
+
+	// rebuilt on every read, so sensors that disappeared are forgotten and
+	// warn again when they come back critical
+	critical := make(map[string]bool)
+	for _, s := range info.Sensors {
+		if !sensors.Critical(s) {
+			continue
+		}
+		critical[s.ID] = true
+		if !h.critical[s.ID] {
+			var msg string
+			switch {
+			case s.Kind == cayman.SensorKindFan && s.Low > 0:
+				msg = fmt.Sprintf("fan %s/%s is at %.0f RPM, minimum is %.0f", s.Chip, s.Label, s.Value, s.Low)
+			case s.Kind == cayman.SensorKindFan:
+				msg = fmt.Sprintf("fan %s/%s is in alarm at %.0f RPM", s.Chip, s.Label, s.Value)
+			default:
+				msg = fmt.Sprintf("sensor %s/%s is at %.1f, critical threshold is %.1f", s.Chip, s.Label, s.Value, s.Critical)
+			}
+			if err := sysevents.PublishSystemEvent(sysevents.SystemEventTypeWarning, msg); err != nil {
+				slog.Error("failed to publish sensor warning", "error", err)
+			}
+		}
+	}
+	h.critical = critical
+
+	e := &sse.Message{
+		Type: sse.Type("sensors"),
+	}
+	bb, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+func (h *DashboardModule) memory() {
+	detail, err := memory.Detail()
+	if err != nil {
+		slog.Error("failed to get memory detail", "error", err)
+		return
+	}
+	h.mu.Lock()
+	h.info.Memory = detail
+	h.mu.Unlock()
+
+	e := &sse.Message{
+		Type: sse.Type("memory"),
+	}
+	bb, err := json.Marshal(detail)
+	if err != nil {
+		return
+	}
+	e.AppendData(string(bb))
+	_ = h.sseHandler.Publish(e, topicHost)
+}
+
+// record appends the values collected during the current tick to the
+// metrics history.
+func (h *DashboardModule) record() {
+	h.mu.RLock()
+	sample := cayman.MetricsSample{
+		Time:    time.Now(),
+		CPU:     h.info.CPU,
+		Load:    h.info.Load,
+		DiskIO:  h.info.DiskIO,
+		Sensors: h.info.Sensors.Sensors,
+		Memory:  h.info.Memory,
+	}
+	h.mu.RUnlock()
+	h.history.Add(sample)
+}
+
+func (h *DashboardModule) historyHandler(c echo.Context) error {
+	return c.JSON(200, h.history.Get())
+}
+
+// cgroupPressureHandler returns the Pressure Stall Information of a single
+// cgroup, selected by one of the unit, container, pid or cgroup query params.
+// Units are looked up in the system manager unless scope=user is given.
+func (h *DashboardModule) cgroupPressureHandler(c echo.Context) error {
+	var (
+		path string
+		err  error
+	)
+	switch {
+	case c.QueryParam("unit") != "":
+		scope, serr := systemd.ParseScope(c.QueryParam("scope"))
+		if serr != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": serr.Error()})
+		}
+		path, err = systemd.ControlGroup(c.Request().Context(), scope, c.QueryParam("unit"))
+	case c.QueryParam("container") != "":
+		path, err = cgroup.ContainerPath(c.QueryParam("container"))
+	case c.QueryParam("pid") != "":
+		pid, perr := strconv.Atoi(c.QueryParam("pid"))
+		if perr != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pid"})
+		}
+		path, err = cgroup.PIDPath(pid)
+	case c.QueryParam("cgroup") != "":
+		path = c.QueryParam("cgroup")
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "one of unit, container, pid or cgroup is required"})
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	psi, err := pressure.Cgroup(path)
+	if errors.Is(err, cgroup.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, cayman.CgroupPressure{
+		Cgroup:   path,
+		Pressure: psi,
+	})
 }
 
 func (h *DashboardModule) hostInfoHandler(c echo.Context) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	return c.JSON(200, h.info)
 }
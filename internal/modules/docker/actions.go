@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"cayman"
+	"cayman/internal/audit"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/labstack/echo/v4"
+)
+
+// containerActions are the actions of containerActionHandler.
+var containerActions = []string{"start", "stop", "restart", "pause", "unpause", "kill", "remove"}
+
+type containerActionRequest struct {
+	// Timeout is the number of seconds stop and restart wait before killing
+	// the container, the container's own stop timeout when omitted.
+	Timeout *int   `json:"timeout"`
+	Signal  string `json:"signal"`  // kill, SIGKILL by default
+	Force   bool   `json:"force"`   // remove, also removes a running container
+	Volumes bool   `json:"volumes"` // remove, also removes anonymous volumes
+}
+
+// containerActionHandler starts, stops, restarts, pauses, unpauses, kills or
+// removes a container and responds with its resulting state.
+func (p *DockerModule) containerActionHandler(c echo.Context) error {
+	action := c.Param("action")
+	if !slices.Contains(containerActions, action) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown action " + action})
+	}
+	var req containerActionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	// :id may also be a name or an ID prefix, while events and the cache
+	// use full IDs
+	inspect, err := p.cli.ContainerInspect(ctx, c.Param("id"))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	id := inspect.ID
+
+	params := make(map[string]string)
+	switch action {
+	case "start":
+		err = p.cli.ContainerStart(ctx, id, container.StartOptions{})
+	case "stop", "restart":
+		opts := container.StopOptions{Timeout: req.Timeout}
+		if req.Timeout != nil {
+			params["timeout"] = strconv.Itoa(*req.Timeout)
+		}
+		if action == "stop" {
+			err = p.cli.ContainerStop(ctx, id, opts)
+		} else {
+			err = p.cli.ContainerRestart(ctx, id, opts)
+		}
+	case "pause":
+		err = p.cli.ContainerPause(ctx, id)
+	case "unpause":
+		err = p.cli.ContainerUnpause(ctx, id)
+	case "kill":
+		if req.Signal == "" {
+			req.Signal = "SIGKILL"
+		}
+		params["signal"] = req.Signal
+		err = p.cli.ContainerKill(ctx, id, req.Signal)
+	case "remove":
+		params["force"] = strconv.FormatBool(req.Force)
+		params["volumes"] = strconv.FormatBool(req.Volumes)
+		err = p.cli.ContainerRemove(ctx, id, container.RemoveOptions{
+			Force:         req.Force,
+			RemoveVolumes: req.Volumes,
+		})
+	}
+	audit.Record(c, "container."+action, id, params, err)
+
+	// publish the change even on failure, a failed start changes the state
+	// too; the daemon's event follows but may come after the response
+	_, _ = p.update(action, id)
+
+	result := cayman.ContainerActionResult{
+		ID:     id,
+		Action: action,
+	}
+	if state, serr := p.containerState(ctx, id); serr == nil {
+		result.State = state
+	} else if action != "remove" || err != nil {
+		err = errors.Join(err, serr)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return c.JSON(errorStatus(err), result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// containerState returns the current state of a container.
+func (p *DockerModule) containerState(ctx context.Context, id string) (*container.State, error) {
+	inspect, err := p.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inspect.ContainerJSONBase == nil || inspect.State == nil {
+		return nil, fmt.Errorf("no state reported for container %s", id)
+	}
+	return inspect.State, nil
+}
+
+// errorStatus maps a Docker API error to a response status code.
+func errorStatus(err error) int {
+	switch {
+	case cerrdefs.IsNotFound(err):
+		return http.StatusNotFound
+	case cerrdefs.IsConflict(err):
+		return http.StatusConflict
+	case cerrdefs.IsInvalidArgument(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -3,14 +3,20 @@ package docker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"cayman"
+	"cayman/internal/auth"
 	syssse "cayman/internal/sse"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"github.com/labstack/echo/v4"
@@ -24,6 +30,32 @@ var (
 	topicHost = "docker"
 )
 
+// resyncInterval is how often the full container and image lists are
+// reloaded. Changes are normally received as events; the resync corrects
+// anything the events did not reflect and keeps the Status of containers,
+// e.g. "Up 5 minutes", current, which no event updates.
+const resyncInterval = 30 * time.Second
+
+// restartDelay is how long Poll waits before subscribing to events again.
+const restartDelay = 5 * time.Second
+
+// ignoredActions are container events that do not change its summary.
+// exec_create and exec_start are matched as prefixes, health checks run
+// them every few seconds.
+var ignoredActions = map[events.Action]bool{
+	events.ActionAttach:       true,
+	events.ActionDetach:       true,
+	events.ActionResize:       true,
+	events.ActionTop:          true,
+	events.ActionCopy:         true,
+	events.ActionArchivePath:  true,
+	events.ActionExtractToDir: true,
+	events.ActionExport:       true,
+	events.ActionCommit:       true,
+	events.ActionExecDie:      true,
+	events.ActionExecDetach:   true,
+}
+
 func init() {
 	dModule = &DockerModule{}
 	cayman.RegisterModule(dModule)
@@ -32,6 +64,17 @@ func init() {
 type DockerModule struct {
 	ctx context.Context
 	sse *sse.Server
+	cli *client.Client
+
+	mu         sync.RWMutex
+	containers map[string]container.Summary
+	images     []image.Summary
+	// err is the last error of the daemon, cleared by a successful resync.
+	// The cache is stale while it is set.
+	err error
+
+	statsMu sync.Mutex
+	stats   map[string]*containerStats
 }
 
 func (p *DockerModule) ShouldEnable() bool {
@@ -50,11 +93,31 @@ func (p *DockerModule) ShouldEnable() bool {
 func (p *DockerModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Group) {
 	p.ctx = ctx
 	p.sse = syssse.NewSSE(topicHost)
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		slog.Error("failed to create docker client", "error", err)
+		return
+	}
+	p.cli = cli
+	p.containers = make(map[string]container.Summary)
+	p.images = make([]image.Summary, 0)
+	p.err = errors.New("docker state not loaded yet")
+	p.stats = make(map[string]*containerStats)
+	go func() {
+		<-ctx.Done()
+		cli.Close()
+	}()
 	// Register Docker-specific routes here
 	routeGroup := parentRoute.Group("/virt/docker")
 	go p.Poll()
+	go p.PollStats()
 	routeGroup.GET("/events", echo.WrapHandler(p.sse))
 	routeGroup.GET("/current", p.dockerInfoHandler)
+	routeGroup.GET("/containers/:id", p.containerInspectHandler)
+	routeGroup.GET("/containers/:id/logs", p.containerLogsHandler)
+	routeGroup.GET("/containers/:id/stats", p.statsHistoryHandler)
+	routeGroup.GET("/containers/:id/exec", p.execHandler, auth.RequireAdmin)
+	routeGroup.POST("/containers/:id/:action", p.containerActionHandler, auth.RequireAdmin)
 }
 
 func (p *DockerModule) Topics() []string {
@@ -65,80 +128,203 @@ func (p *DockerModule) Name() string {
 	return "Docker"
 }
 
+// Poll keeps the cache up to date from the daemon's events and publishes
+// every change. The events stream is subscribed again after a failure, such
+// as a daemon restart, and followed by a full resync since events may have
+// been missed.
 func (p *DockerModule) Poll() {
-	ticker := time.NewTicker(3 * time.Second)
+	for {
+		p.watch()
+		if p.ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// watch handles events until the stream fails or the context is done.
+func (p *DockerModule) watch() {
+	ctx, cancel := context.WithCancel(p.ctx)
+	defer cancel()
+	msgs, errs := p.cli.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("type", string(events.ImageEventType)),
+			filters.Arg("type", string(events.NetworkEventType)),
+		),
+	})
+	// resync after subscribing so no change falls between the two
+	p.resync()
+
+	ticker := time.NewTicker(resyncInterval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-p.ctx.Done():
 			return
 		case <-ticker.C:
-			info, err := getDockerInfo()
-			if err != nil {
-				slog.Error("docker poll error", "error", err)
-				continue
-			}
-			cc, err := json.Marshal(info.Containers)
-			if err != nil {
-				slog.Error("docker marshal error", "error", err)
-				continue
-			}
-			event := &sse.Message{
-				Type: sse.Type("containers"),
-			}
-			event.AppendData(string(cc))
-			p.sse.Publish(event, topicHost)
-			ii, err := json.Marshal(info.Images)
-			if err != nil {
-				slog.Error("docker marshal error", "error", err)
-				continue
-			}
-			event = &sse.Message{
-				Type: sse.Type("images"),
-			}
-			event.AppendData(string(ii))
-			p.sse.Publish(event, topicHost)
+			p.resync()
+		case err := <-errs:
+			slog.Error("docker events stopped", "error", err)
+			p.setErr(err)
+			return
+		case msg := <-msgs:
+			p.handle(msg)
 		}
 	}
 }
 
-func (p *DockerModule) dockerInfoHandler(c echo.Context) error {
-	info, err := getDockerInfo()
+// handle updates the cache from a daemon event.
+func (p *DockerModule) handle(msg events.Message) {
+	switch msg.Type {
+	case events.ContainerEventType:
+		if ignoredActions[msg.Action] || strings.HasPrefix(string(msg.Action), string(events.ActionExecCreate)) ||
+			strings.HasPrefix(string(msg.Action), string(events.ActionExecStart)) {
+			return
+		}
+		_, _ = p.update(string(msg.Action), msg.Actor.ID)
+	case events.NetworkEventType:
+		// connecting and disconnecting change the networks of a container
+		if id := msg.Actor.Attributes["container"]; id != "" {
+			_, _ = p.update(string(msg.Action), id)
+		}
+	case events.ImageEventType:
+		p.refreshImages()
+	}
+}
+
+// resync reloads every container and image and publishes the full lists.
+func (p *DockerModule) resync() {
+	containers, err := p.cli.ContainerList(p.ctx, container.ListOptions{All: true})
 	if err != nil {
-		return c.JSON(500, map[string]string{"error": err.Error()})
+		slog.Error("failed to list docker containers", "error", err)
+		p.setErr(err)
+		return
+	}
+	m := make(map[string]container.Summary, len(containers))
+	for _, c := range containers {
+		m[c.ID] = c
+	}
+	p.mu.Lock()
+	p.containers = m
+	p.mu.Unlock()
+	p.syncStats()
+	p.publish("containers", p.containerList())
+	if p.refreshImages() {
+		p.setErr(nil)
+	}
+}
+
+// setErr records the last error of the daemon, nil once it is reachable
+// again.
+func (p *DockerModule) setErr(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+}
+
+// refreshImages reloads the images and publishes them. Image events are
+// rare and carry no summary, so the whole list is reloaded each time. It
+// reports whether the images could be listed.
+func (p *DockerModule) refreshImages() bool {
+	images, err := p.cli.ImageList(p.ctx, image.ListOptions{All: true})
+	if err != nil {
+		slog.Error("failed to list docker images", "error", err)
+		p.setErr(err)
+		return false
 	}
-	return c.JSON(200, info)
+	// Sort images by Created field in descending order (newest first)
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].Created > images[j].Created
+	})
+	p.mu.Lock()
+	p.images = images
+	p.mu.Unlock()
+	p.publish("images", images)
+	return true
 }
 
-func getDockerInfo() (*cayman.DockerInfo, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+// update refreshes a container and publishes the change. The container is
+// dropped from the cache, and reported as nil, once it no longer exists.
+func (p *DockerModule) update(action, id string) (*container.Summary, error) {
+	containers, err := p.cli.ContainerList(p.ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("id", id)),
+	})
 	if err != nil {
+		slog.Error("failed to get docker container", "id", id, "error", err)
 		return nil, err
 	}
-	defer cli.Close()
+	change := cayman.ContainerEvent{
+		ID:     id,
+		Action: action,
+	}
+	p.mu.Lock()
+	// the id filter matches prefixes, keep the exact match only
+	for i := range containers {
+		if containers[i].ID == id {
+			change.Container = &containers[i]
+		}
+	}
+	if change.Container != nil {
+		p.containers[id] = *change.Container
+	} else {
+		delete(p.containers, id)
+	}
+	p.mu.Unlock()
+	p.syncStats()
+	p.publish("container", change)
+	// the docker page replaces the whole list, taken from the cache
+	p.publish("containers", p.containerList())
+	return change.Container, nil
+}
 
-	containers, err := cli.ContainerList(context.Background(), container.ListOptions{All: true})
+// publish sends data as an event of the given type on the docker topic.
+func (p *DockerModule) publish(eventType string, data any) {
+	bb, err := json.Marshal(data)
 	if err != nil {
-		return nil, err
+		slog.Error("docker marshal error", "error", err)
+		return
 	}
+	event := &sse.Message{
+		Type: sse.Type(eventType),
+	}
+	event.AppendData(string(bb))
+	_ = p.sse.Publish(event, topicHost)
+}
+
+// containerList returns the cached containers, newest first.
+func (p *DockerModule) containerList() []container.Summary {
+	p.mu.RLock()
+	containers := make([]container.Summary, 0, len(p.containers))
+	for _, c := range p.containers {
+		containers = append(containers, c)
+	}
+	p.mu.RUnlock()
 
 	// Sort containers by Created field in descending order (newest first)
 	sort.Slice(containers, func(i, j int) bool {
-		return containers[i].Created > containers[j].Created
+		if containers[i].Created != containers[j].Created {
+			return containers[i].Created > containers[j].Created
+		}
+		return containers[i].ID < containers[j].ID
 	})
+	return containers
+}
 
-	images, err := cli.ImageList(context.Background(), image.ListOptions{All: true})
+func (p *DockerModule) dockerInfoHandler(c echo.Context) error {
+	p.mu.RLock()
+	images, err := p.images, p.err
+	p.mu.RUnlock()
 	if err != nil {
-		return nil, err
+		return c.JSON(500, map[string]string{"error": err.Error()})
 	}
-
-	// Sort images by Created field in descending order (newest first)
-	sort.Slice(images, func(i, j int) bool {
-		return images[i].Created > images[j].Created
-	})
-
-	return &cayman.DockerInfo{
-		Containers: containers,
+	return c.JSON(200, &cayman.DockerInfo{
+		Containers: p.containerList(),
 		Images:     images,
-	}, nil
+	})
 }
@@ -0,0 +1,195 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+	"cayman/internal/audit"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// terminalProtocol is the WebSocket subprotocol of exec terminals.
+const terminalProtocol = "cayman.terminal"
+
+// defaultShell is run when no command is given.
+const defaultShell = "/bin/sh"
+
+// execExitWait bounds how long the exit code of an exec is waited for after
+// its output ended.
+const execExitWait = 2 * time.Second
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{terminalProtocol},
+}
+
+// execHandler runs a command in a container with a TTY and relays it over a
+// WebSocket: output as binary messages, stdin and resize as TerminalMessage
+// text messages, and an exit message with the exit code once the command
+// ends. When the command cannot be started, an error message is sent
+// instead.
+//
+// Query params:
+//   - cmd: command and arguments, repeated, /bin/sh by default
+//   - user: user to run the command as, the container's user by default
+//   - cols, rows: initial terminal size
+func (p *DockerModule) execHandler(c echo.Context) error {
+	id := c.Param("id")
+	cmd := c.QueryParams()["cmd"]
+	if len(cmd) == 0 {
+		cmd = []string{defaultShell}
+	}
+	opts := container.ExecOptions{
+		User:         c.QueryParam("user"),
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+		Cmd:          cmd,
+	}
+	if c.QueryParam("cols") != "" || c.QueryParam("rows") != "" {
+		cols, cerr := strconv.ParseUint(c.QueryParam("cols"), 10, 16)
+		rows, rerr := strconv.ParseUint(c.QueryParam("rows"), 10, 16)
+		if cerr != nil || rerr != nil || cols == 0 || rows == 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid terminal size"})
+		}
+		opts.ConsoleSize = &[2]uint{uint(rows), uint(cols)}
+	}
+
+	// upgrade first, so nothing runs in the container for a request that
+	// is not a WebSocket or fails the origin check
+	ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader has already responded
+		return nil
+	}
+	defer ws.Close()
+
+	ctx := c.Request().Context()
+	params := map[string]string{
+		"cmd":  strings.Join(cmd, " "),
+		"user": opts.User,
+	}
+	exec, err := p.cli.ContainerExecCreate(ctx, id, opts)
+	if err == nil {
+		params["exec"] = exec.ID
+	}
+	var hijack types.HijackedResponse
+	if err == nil {
+		hijack, err = p.cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{
+			Tty:         true,
+			ConsoleSize: opts.ConsoleSize,
+		})
+	}
+	audit.Record(c, "container.exec", id, params, err)
+	if err != nil {
+		if werr := ws.WriteJSON(cayman.TerminalMessage{Type: "error", Data: err.Error()}); werr == nil {
+			_ = ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseInternalServerErr, ""), time.Now().Add(time.Second))
+		}
+		return nil
+	}
+	defer hijack.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relayOutput(ws, hijack.Reader)
+		// the command ended, or the client went away and nothing can be
+		// sent any more
+		msg := cayman.TerminalMessage{Type: "exit"}
+		msg.ExitCode = p.execExitCode(exec.ID)
+		if err := ws.WriteJSON(msg); err == nil {
+			_ = ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		}
+	}()
+
+	for {
+		kind, data, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		if kind != websocket.TextMessage {
+			continue
+		}
+		var msg cayman.TerminalMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case "stdin":
+			if _, err := io.WriteString(hijack.Conn, msg.Data); err != nil {
+				slog.Error("docker exec stdin error", "id", id, "error", err)
+			}
+		case "resize":
+			if msg.Cols == 0 || msg.Rows == 0 {
+				continue
+			}
+			if err := p.cli.ContainerExecResize(ctx, exec.ID, container.ResizeOptions{
+				Height: msg.Rows,
+				Width:  msg.Cols,
+			}); err != nil {
+				slog.Error("docker exec resize error", "id", id, "error", err)
+			}
+		}
+	}
+	// the client went away or the exit message was sent, closing the
+	// stream hangs up the command if it still runs
+	hijack.Close()
+	<-done
+	return nil
+}
+
+// execExitCode returns the exit code of an exec whose output has ended. The
+// daemon may still report it as running for a moment, so it is asked again
+// until it is not, for up to execExitWait.
+func (p *DockerModule) execExitCode(execID string) int {
+	deadline := time.Now().Add(execExitWait)
+	for {
+		inspect, err := p.cli.ContainerExecInspect(p.ctx, execID)
+		if err != nil {
+			return -1
+		}
+		if !inspect.Running || time.Now().After(deadline) {
+			return inspect.ExitCode
+		}
+		select {
+		case <-p.ctx.Done():
+			return -1
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// relayOutput copies the output of a command to the WebSocket until either
+// side is closed.
+func relayOutput(ws *websocket.Conn, r io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if werr := ws.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, context.Canceled) {
+				slog.Debug("docker exec output closed", "error", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"net/http"
+
+	"cayman/internal/auth"
+	"cayman/internal/redact"
+
+	"github.com/labstack/echo/v4"
+)
+
+// containerInspectHandler returns the full configuration and state of a
+// container as reported by the daemon. Secret-looking values of the
+// environment, command line and labels are masked unless the request has
+// the admin role.
+func (p *DockerModule) containerInspectHandler(c echo.Context) error {
+	inspect, err := p.cli.ContainerInspect(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	if auth.IsAdmin(c) {
+		return c.JSON(http.StatusOK, inspect)
+	}
+	if inspect.ContainerJSONBase != nil {
+		// Path is the first argument of the command line, Args the rest
+		cmd := redact.Args(append([]string{inspect.Path}, inspect.Args...))
+		inspect.Path, inspect.Args = cmd[0], cmd[1:]
+	}
+	if cfg := inspect.Config; cfg != nil {
+		cfg.Env = redact.Env(cfg.Env)
+		cfg.Cmd = redact.Args(cfg.Cmd)
+		cfg.Entrypoint = redact.Args(cfg.Entrypoint)
+		cfg.Labels = redact.Labels(cfg.Labels)
+		if cfg.Healthcheck != nil {
+			cfg.Healthcheck.Test = redact.Args(cfg.Healthcheck.Test)
+		}
+	}
+	return c.JSON(http.StatusOK, inspect)
+}
@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/labstack/echo/v4"
+	"github.com/tmaxmax/go-sse"
+)
+
+// maxLogLine bounds the buffered part of a log line; longer lines are sent
+// in pieces of this size.
+const maxLogLine = 64 * 1024
+
+// containerLogsHandler streams the logs of a container as log events, one per
+// line, followed by an end event when the stream is over.
+//
+// Query params:
+//   - since: RFC 3339 timestamp, Unix timestamp or duration such as 10m
+//   - tail: number of lines to start with, all by default
+//   - timestamps: true to set the time of each line
+//   - follow: false to stop after the existing lines, true by default
+func (p *DockerModule) containerLogsHandler(c echo.Context) error {
+	id := c.Param("id")
+	opts := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Since:      c.QueryParam("since"),
+		Tail:       c.QueryParam("tail"),
+		Follow:     true,
+	}
+	if opts.Tail != "" && opts.Tail != "all" {
+		if n, err := strconv.Atoi(opts.Tail); err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid tail " + opts.Tail})
+		}
+	}
+	for _, b := range []struct {
+		param string
+		value *bool
+	}{{"timestamps", &opts.Timestamps}, {"follow", &opts.Follow}} {
+		if s := c.QueryParam(b.param); s != "" {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid " + b.param})
+			}
+			*b.value = v
+		}
+	}
+
+	ctx := c.Request().Context()
+	// the output of containers with a TTY is not multiplexed
+	inspect, err := p.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	rc, err := p.cli.ContainerLogs(ctx, id, opts)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	defer rc.Close()
+
+	sess, err := sse.Upgrade(c.Response(), c.Request())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	stdout := &logWriter{sess: sess, stream: "stdout", timestamps: opts.Timestamps}
+	stderr := &logWriter{sess: sess, stream: "stderr", timestamps: opts.Timestamps}
+	if inspect.Config != nil && inspect.Config.Tty {
+		_, err = io.Copy(stdout, rc)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, rc)
+	}
+	if ctx.Err() != nil {
+		// the client went away
+		return nil
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("docker logs stream error", "id", id, "error", err)
+	}
+	// lines cut short by the end of the stream
+	stdout.flush()
+	stderr.flush()
+	end := &sse.Message{
+		Type: sse.Type("end"),
+	}
+	end.AppendData("{}")
+	_ = sess.Send(end)
+	_ = sess.Flush()
+	return nil
+}
+
+// logWriter sends every complete line written to it as a log event.
+type logWriter struct {
+	sess       *sse.Session
+	stream     string
+	timestamps bool
+	buf        []byte
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	w.buf = append(w.buf, b...)
+	for {
+		line, rest, ok := bytes.Cut(w.buf, []byte("\n"))
+		if !ok {
+			if len(w.buf) < maxLogLine {
+				break
+			}
+			line, rest = w.buf[:maxLogLine], w.buf[maxLogLine:]
+		}
+		if err := w.send(line); err != nil {
+			return 0, err
+		}
+		w.buf = rest
+	}
+	if err := w.sess.Flush(); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// flush sends the remaining partial line, if any.
+func (w *logWriter) flush() {
+	if len(w.buf) > 0 {
+		_ = w.send(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *logWriter) send(line []byte) error {
+	l := cayman.ContainerLogLine{
+		Stream: w.stream,
+	}
+	msg := strings.TrimSuffix(string(line), "\r")
+	if w.timestamps {
+		// the daemon prefixes each line with its RFC 3339 time and a space
+		if ts, rest, ok := strings.Cut(msg, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				l.Time = &t
+				msg = rest
+			}
+		}
+	}
+	l.Message = msg
+	bb, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	event := &sse.Message{
+		Type: sse.Type("log"),
+	}
+	event.AppendData(string(bb))
+	return w.sess.Send(event)
+}
@@ -0,0 +1,261 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"cayman"
+	"cayman/internal/ringbuffer"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/labstack/echo/v4"
+)
+
+// statsInterval is how often the latest stats of all running containers are
+// published and added to their history.
+const statsInterval = 3 * time.Second
+
+// statsHistorySize is the number of samples kept per container, 5 minutes.
+const statsHistorySize = 100
+
+// containerStats follows the stats stream of a running container.
+type containerStats struct {
+	cancel  context.CancelFunc
+	latest  *cayman.ContainerStats // nil until the second sample
+	history *ringbuffer.RingBuffer[cayman.ContainerStats]
+}
+
+// PollStats publishes a containerstats event with the latest stats of every
+// running container. Nothing is published while no container has stats,
+// except one empty list when the last one stops.
+func (p *DockerModule) PollStats() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	// whether the last event had samples, so idle hosts do not fill the
+	// replay buffer with empty lists
+	var active bool
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.statsMu.Lock()
+			samples := make([]cayman.ContainerStats, 0, len(p.stats))
+			for _, s := range p.stats {
+				if s.latest != nil {
+					s.history.Add(*s.latest)
+					samples = append(samples, *s.latest)
+				}
+			}
+			p.statsMu.Unlock()
+			if len(samples) == 0 && !active {
+				continue
+			}
+			active = len(samples) > 0
+			sort.Slice(samples, func(i, j int) bool {
+				return samples[i].Name < samples[j].Name
+			})
+			p.publish("containerstats", samples)
+		}
+	}
+}
+
+// syncStats follows the stats of the running containers in the cache and
+// stops following the others.
+func (p *DockerModule) syncStats() {
+	running := make(map[string]bool)
+	p.mu.RLock()
+	for id, c := range p.containers {
+		if c.State == container.StateRunning {
+			running[id] = true
+		}
+	}
+	p.mu.RUnlock()
+
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	for id, s := range p.stats {
+		if !running[id] {
+			s.cancel()
+			delete(p.stats, id)
+		}
+	}
+	for id := range running {
+		if _, ok := p.stats[id]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(p.ctx)
+		s := &containerStats{
+			cancel:  cancel,
+			history: ringbuffer.New[cayman.ContainerStats](statsHistorySize),
+		}
+		p.stats[id] = s
+		go p.streamStats(ctx, id, s)
+	}
+}
+
+// streamStats reads the stats stream of a container until it stops or ctx is
+// done. The daemon sends a sample about every second.
+func (p *DockerModule) streamStats(ctx context.Context, id string, s *containerStats) {
+	defer func() {
+		// forget the container so the next sync follows it again, unless it
+		// was replaced in the meantime
+		s.cancel()
+		p.statsMu.Lock()
+		if p.stats[id] == s {
+			delete(p.stats, id)
+		}
+		p.statsMu.Unlock()
+	}()
+
+	resp, err := p.cli.ContainerStats(ctx, id, true)
+	if err != nil {
+		slog.Error("failed to get docker container stats", "id", id, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	var prev *container.StatsResponse
+	for {
+		var cur container.StatsResponse
+		if err := dec.Decode(&cur); err != nil {
+			if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+				slog.Error("docker stats stream error", "id", id, "error", err)
+			}
+			return
+		}
+		if prev != nil {
+			stats := computeStats(id, prev, &cur)
+			p.statsMu.Lock()
+			s.latest = &stats
+			p.statsMu.Unlock()
+		}
+		prev = &cur
+	}
+}
+
+// computeStats derives a sample from two consecutive stats, the way docker
+// stats does.
+func computeStats(id string, prev, cur *container.StatsResponse) cayman.ContainerStats {
+	stats := cayman.ContainerStats{
+		ID:          id,
+		Name:        strings.TrimPrefix(cur.Name, "/"),
+		Time:        cur.Read,
+		MemoryLimit: cur.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(cur.CPUStats.CPUUsage.TotalUsage) - float64(cur.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(cur.CPUStats.SystemUsage) - float64(cur.PreCPUStats.SystemUsage)
+	cpus := float64(cur.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		// reported by cgroup v1 only
+		cpus = float64(len(cur.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * cpus * 100
+	}
+
+	stats.MemoryUsage = cur.MemoryStats.Usage
+	// as docker stats: total_inactive_file on cgroup v1, which also has
+	// inactive_file, and inactive_file on v2
+	for _, key := range []string{"total_inactive_file", "inactive_file"} {
+		if v, ok := cur.MemoryStats.Stats[key]; ok && v < stats.MemoryUsage {
+			stats.MemoryUsage -= v
+			break
+		}
+	}
+
+	stats.NetRx, stats.NetTx = netTotals(cur)
+	stats.BlockRead, stats.BlockWrite = blockTotals(cur)
+	if elapsed := cur.Read.Sub(prev.Read).Seconds(); elapsed > 0 {
+		rx, tx := netTotals(prev)
+		read, write := blockTotals(prev)
+		stats.NetRxRate = rate(rx, stats.NetRx, elapsed)
+		stats.NetTxRate = rate(tx, stats.NetTx, elapsed)
+		stats.BlockReadRate = rate(read, stats.BlockRead, elapsed)
+		stats.BlockWriteRate = rate(write, stats.BlockWrite, elapsed)
+	}
+	return stats
+}
+
+func netTotals(s *container.StatsResponse) (rx, tx uint64) {
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+func blockTotals(s *container.StatsResponse) (read, write uint64) {
+	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			read += e.Value
+		case "write":
+			write += e.Value
+		}
+	}
+	return read, write
+}
+
+// rate is the per second increase of a counter, 0 when it was reset.
+func rate(prev, cur uint64, elapsed float64) float64 {
+	if cur < prev {
+		return 0
+	}
+	return float64(cur-prev) / elapsed
+}
+
+// statsHistoryHandler returns the recent stats of a running container, oldest
+// first.
+func (p *DockerModule) statsHistoryHandler(c echo.Context) error {
+	id, ok := p.lookup(c.Param("id"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "no such container " + c.Param("id")})
+	}
+	p.statsMu.Lock()
+	s, ok := p.stats[id]
+	p.statsMu.Unlock()
+	if !ok {
+		return c.JSON(http.StatusOK, []cayman.ContainerStats{})
+	}
+	return c.JSON(http.StatusOK, s.history.Get())
+}
+
+// lookup finds a cached container by ID, unique ID prefix or name.
+func (p *DockerModule) lookup(ref string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if _, ok := p.containers[ref]; ok {
+		return ref, true
+	}
+	var found []string
+	for id, c := range p.containers {
+		if strings.HasPrefix(id, ref) || hasName(c.Names, ref) {
+			found = append(found, id)
+		}
+	}
+	if len(found) != 1 {
+		return "", false
+	}
+	return found[0], true
+}
+
+// hasName reports whether ref is one of the names of a container,
+// which the daemon reports with a leading slash.
+func hasName(names []string, ref string) bool {
+	for _, name := range names {
+		if strings.TrimPrefix(name, "/") == ref {
+			return true
+		}
+	}
+	return false
+}
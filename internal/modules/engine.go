@@ -11,6 +11,7 @@ import (
 
 	"cayman"
 	"cayman/frontend"
+	"cayman/internal/auth"
 	_ "cayman/internal/modules/dashboard"
 	_ "cayman/internal/modules/docker"
 	_ "cayman/internal/modules/host"
@@ -18,9 +19,10 @@ import (
 	_ "cayman/internal/modules/logs"
 	_ "cayman/internal/modules/metrics"
 	_ "cayman/internal/modules/podman"
+	_ "cayman/internal/modules/processes"
 	_ "cayman/internal/modules/storage"
 	_ "cayman/internal/modules/system"
-
+	_ "cayman/internal/modules/systemd"
 	"cayman/internal/system"
 
 	"github.com/labstack/echo/v4"
@@ -33,13 +35,15 @@ type Engine struct {
 	httpServer *http.Server
 	listenAddr string
 	port       string
+	adminToken string
 }
 
-func NewEngine(logger *slog.Logger, listenAddr string, port string) *Engine {
+func NewEngine(logger *slog.Logger, listenAddr string, port string, adminToken string) *Engine {
 	return &Engine{
 		logger:     logger,
 		listenAddr: listenAddr,
 		port:       port,
+		adminToken: adminToken,
 	}
 }
 
@@ -79,6 +83,10 @@ func (e *Engine) Start(ctx context.Context) error {
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{"*"},
 	}))
+	app.Use(auth.Middleware(e.adminToken))
+	if e.adminToken == "" {
+		slog.Warn("no admin token configured, privileged endpoints are disabled")
+	}
 
 	api := app.Group("/api")
 
@@ -0,0 +1,113 @@
+package logs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"cayman"
+	"cayman/internal/data/journal"
+	"cayman/internal/data/logfile"
+	"cayman/internal/ringbuffer"
+)
+
+const (
+	// tailInterval is how often tailed log files are checked for new lines.
+	tailInterval = time.Second
+	// fileHistorySize is the number of tailed entries kept for queries, the
+	// files themselves are not searched.
+	fileHistorySize = 10000
+	// fileCursorPrefix marks cursors of tailed entries, which are sequence
+	// numbers rather than journal cursors.
+	fileCursorPrefix = "file:"
+)
+
+// fileSources are the log files to tail, set before the module is enabled.
+var fileSources []logfile.Source
+
+// SetFileSources configures plain-text log files to tail alongside the
+// journal. It must be called before the server starts.
+func SetFileSources(sources []logfile.Source) {
+	fileSources = sources
+}
+
+// fileLogs keeps the most recent entries of the tailed files.
+type fileLogs struct {
+	tailer  *logfile.Tailer
+	history *ringbuffer.RingBuffer[cayman.LogEntry]
+	seq     uint64
+}
+
+func newFileLogs(sources []logfile.Source) *fileLogs {
+	return &fileLogs{
+		tailer:  logfile.NewTailer(sources),
+		history: ringbuffer.New[cayman.LogEntry](fileHistorySize),
+	}
+}
+
+// tail polls the log files and publishes their new lines like journal
+// entries.
+func (p *LogsModule) tail() {
+	defer p.files.tailer.Close()
+	ticker := time.NewTicker(tailInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.files.tailer.Poll(func(e cayman.LogEntry) {
+				p.files.seq++
+				e.Cursor = fileCursorPrefix + strconv.FormatUint(p.files.seq, 10)
+				p.files.history.Add(e)
+				p.publish(e)
+			})
+		}
+	}
+}
+
+// page returns the kept entries matching q, newest first.
+func (f *fileLogs) page(q journal.Query) cayman.LogPage {
+	if q.Limit <= 0 {
+		q.Limit = journal.DefaultLimit
+	}
+	q.Limit = min(q.Limit, journal.MaxLimit)
+	before := uint64(0)
+	if s, ok := strings.CutPrefix(q.Cursor, fileCursorPrefix); ok {
+		before, _ = strconv.ParseUint(s, 10, 64)
+	}
+
+	page := cayman.LogPage{Entries: make([]cayman.LogEntry, 0)}
+	entries := f.history.Get()
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if before > 0 && cursorSeq(e.Cursor) >= before {
+			continue
+		}
+		if !q.Filter.Match(e) {
+			continue
+		}
+		if len(page.Entries) == q.Limit {
+			page.Next = page.Entries[q.Limit-1].Cursor
+			break
+		}
+		page.Entries = append(page.Entries, e)
+	}
+	return page
+}
+
+// entries returns the kept entries matching f, oldest first.
+func (f *fileLogs) entries(filter journal.Filter) []cayman.LogEntry {
+	var entries []cayman.LogEntry
+	for _, e := range f.history.Get() {
+		if filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func cursorSeq(cursor string) uint64 {
+	seq, _ := strconv.ParseUint(strings.TrimPrefix(cursor, fileCursorPrefix), 10, 64)
+	return seq
+}
@@ -1,10 +1,26 @@
 package logs
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"cayman"
-	syssse "cayman/internal/sse"
+	"cayman/internal/data/journal"
+	"cayman/internal/data/logfile"
+	"cayman/internal/data/system"
+	sysevents "cayman/internal/system"
 
 	"github.com/labstack/echo/v4"
 	"github.com/tmaxmax/go-sse"
@@ -17,6 +33,18 @@ var (
 	topicHost = "logs"
 )
 
+// restartDelay is how long Poll waits before following the journal again
+// after journalctl exited.
+const restartDelay = 5 * time.Second
+
+// kernelWarningInterval limits warnings for the same kernel problem, such as
+// a failing disk logging an I/O error for every request.
+const kernelWarningInterval = 10 * time.Minute
+
+// maxWarned is the number of remembered kernel problems above which expired
+// ones are forgotten.
+const maxWarned = 256
+
 func init() {
 	lModule = &LogsModule{}
 	cayman.RegisterModule(lModule)
@@ -25,21 +53,51 @@ func init() {
 type LogsModule struct {
 	ctx context.Context
 	sse *sse.Server
+	// journal is set when journalctl is available
+	journal bool
+	files   *fileLogs
+	// sessions maps the topic of each connected client to its filter, every
+	// client is subscribed to a topic of its own.
+	mu       sync.RWMutex
+	sessions map[string]journal.Filter
+	nextID   atomic.Uint64
+	// warned holds when a warning was last raised for each kernel problem,
+	// only accessed from Poll.
+	warned map[string]time.Time
 }
 
 func (p *LogsModule) ShouldEnable() bool {
-	// Logic to determine if the Logs module should be enabled
-	return true
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		slog.Error("journalctl not found, journal logs are disabled", "error", err)
+	} else {
+		p.journal = true
+	}
+	return p.journal || len(fileSources) > 0
 }
 
 func (p *LogsModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Group) {
 	p.ctx = ctx
-	p.sse = syssse.NewSSE(topicHost)
-	// Register Logs-specific routes here
+	p.sessions = make(map[string]journal.Filter)
+	p.warned = make(map[string]time.Time)
+	p.sse = &sse.Server{
+		// entries are not replayed, a reconnecting client gets a new topic
+		Provider:  &sse.Joe{},
+		OnSession: p.onSession,
+	}
 	routeGroup := parentRoute.Group("/logs")
-	go p.Poll()
+	if p.journal {
+		go p.Poll()
+	}
+	if len(fileSources) > 0 {
+		p.files = newFileLogs(fileSources)
+		go p.tail()
+	}
 	routeGroup.GET("/events", echo.WrapHandler(p.sse))
 	routeGroup.GET("/current", p.logsInfoHandler)
+	routeGroup.GET("/kernel", p.logsInfoHandler, kernelOnly)
+	routeGroup.GET("/kernel/events", echo.WrapHandler(p.sse), kernelOnly)
+	routeGroup.GET("/export", p.exportHandler)
+	routeGroup.GET("/boots", p.bootsHandler)
 }
 
 func (p *LogsModule) Topics() []string {
@@ -50,11 +108,268 @@ func (p *LogsModule) Name() string {
 	return "Logs"
 }
 
+// Poll follows the journal and publishes a log event for every new entry to
+// the clients whose filter it matches. When journalctl exits it is restarted
+// after the last entry seen, so no entries are lost in between.
 func (p *LogsModule) Poll() {
-	// Logic to poll Logs for updates
+	var cursor string
+	for {
+		err := journal.Follow(p.ctx, cursor, func(e cayman.LogEntry) {
+			cursor = e.Cursor
+			p.publish(e)
+			p.checkKernel(e)
+		})
+		if p.ctx.Err() != nil {
+			return
+		}
+		slog.Error("journal follow stopped", "error", err)
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+	}
+}
+
+// checkKernel raises a system warning for kernel messages reporting a known
+// problem, at most once per kernelWarningInterval for the same problem.
+func (p *LogsModule) checkKernel(e cayman.LogEntry) {
+	ev, ok := journal.DetectKernelEvent(e)
+	if !ok {
+		return
+	}
+	key := ev.Kind + "/" + ev.Subject
+	if last, ok := p.warned[key]; ok && e.Time.Sub(last) < kernelWarningInterval {
+		return
+	}
+	if len(p.warned) > maxWarned {
+		for k, t := range p.warned {
+			if e.Time.Sub(t) >= kernelWarningInterval {
+				delete(p.warned, k)
+			}
+		}
+	}
+	p.warned[key] = e.Time
+	if err := sysevents.PublishSystemEvent(sysevents.SystemEventTypeWarning, ev.Summary+"\n"+e.Message); err != nil {
+		slog.Error("failed to publish kernel warning", "error", err)
+	}
+}
+
+// kernelOnly restricts the logs of a route to kernel messages.
+func kernelOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		q := c.Request().URL.Query()
+		q.Set("transport", journal.TransportKernel)
+		c.Request().URL.RawQuery = q.Encode()
+		return next(c)
+	}
 }
 
+// onSession subscribes a client to a topic of its own, receiving the entries
+// that match the filter given in its query params.
+func (p *LogsModule) onSession(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	filter, err := journal.ParseFilter(r.Context(), r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	topic := topicHost + "/" + strconv.FormatUint(p.nextID.Add(1), 10)
+	p.mu.Lock()
+	p.sessions[topic] = filter
+	p.mu.Unlock()
+	go func() {
+		<-r.Context().Done()
+		p.mu.Lock()
+		delete(p.sessions, topic)
+		p.mu.Unlock()
+	}()
+	// the shutdown message is sent on the default topic
+	return []string{topic, sse.DefaultTopic}, true
+}
+
+// publish sends an entry to the clients whose filter it matches.
+func (p *LogsModule) publish(e cayman.LogEntry) {
+	var topics []string
+	p.mu.RLock()
+	for topic, filter := range p.sessions {
+		if filter.Match(e) {
+			topics = append(topics, topic)
+		}
+	}
+	p.mu.RUnlock()
+	if len(topics) == 0 {
+		return
+	}
+
+	bb, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("logs marshal error", "error", err)
+		return
+	}
+	event := &sse.Message{
+		Type: sse.Type("log"),
+	}
+	event.AppendData(string(bb))
+	_ = p.sse.Publish(event, topics...)
+}
+
+// logsInfoHandler returns a page of journal entries, newest first. Entries
+// of tailed log files are returned instead when the filter selects the file
+// transport or a source, or when journalctl is not available.
+//
+// Query params, besides the filters of journal.ParseFilter:
+//   - cursor: the next cursor of the previous page, to continue with older
+//     entries
+//   - limit: page size, 100 by default and at most 1000
 func (p *LogsModule) logsInfoHandler(c echo.Context) error {
-	// Logic to handle logs info requests
+	filter, err := journal.ParseFilter(c.Request().Context(), c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	q := journal.Query{
+		Filter: filter,
+		Cursor: c.QueryParam("cursor"),
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		q.Limit = n
+	}
+	if p.fromFiles(filter) {
+		if p.files == nil {
+			return c.JSON(http.StatusOK, cayman.LogPage{Entries: []cayman.LogEntry{}})
+		}
+		return c.JSON(http.StatusOK, p.files.page(q))
+	}
+	page, err := journal.Read(c.Request().Context(), q)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+// fromFiles reports whether queries with filter are served from the tailed
+// log files rather than the journal.
+func (p *LogsModule) fromFiles(filter journal.Filter) bool {
+	return !p.journal || filter.Transport == logfile.TransportFile || filter.Source != ""
+}
+
+// bootsHandler lists the boots recorded in the journal, newest first. The
+// list is empty when journalctl is not available.
+func (p *LogsModule) bootsHandler(c echo.Context) error {
+	if !p.journal {
+		return c.JSON(http.StatusOK, []cayman.Boot{})
+	}
+	boots, err := journal.ListBoots(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, boots)
+}
+
+// exportExtensions are the file extensions of the export formats.
+var exportExtensions = map[string]string{
+	journal.FormatText:   ".log",
+	journal.FormatJSON:   ".jsonl",
+	journal.FormatExport: ".journal",
+}
+
+var exportContentTypes = map[string]string{
+	journal.FormatText:   "text/plain; charset=utf-8",
+	journal.FormatJSON:   "application/x-ndjson",
+	journal.FormatExport: "application/vnd.fdo.journal",
+}
+
+// exportHandler downloads every entry matching the filters of
+// journal.ParseFilter, oldest first, preceded by a header identifying the
+// host. When journalctl fails before writing anything, it responds with
+// the error instead. Like logsInfoHandler, the kept entries of tailed log
+// files are exported instead of the journal when the filter selects them or
+// journalctl is not available.
+//
+// Query params:
+//   - format: text (default), json or export
+//   - gzip: compress the download when true
+func (p *LogsModule) exportHandler(c echo.Context) error {
+	filter, err := journal.ParseFilter(c.Request().Context(), c.QueryParams())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = journal.FormatText
+	}
+	if !slices.Contains(journal.Formats, format) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be one of " + strings.Join(journal.Formats, ", ")})
+	}
+	compress, _ := strconv.ParseBool(c.QueryParam("gzip"))
+
+	// start journalctl before responding, so a failing query gets an error
+	// rather than an empty download
+	var export *journal.Export
+	if !p.fromFiles(filter) {
+		export, err = journal.StartExport(c.Request().Context(), filter, format)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	header := exportHeader(c.Request().Context(), c.QueryString())
+	name := "journal"
+	if header.Hostname != "" {
+		name = header.Hostname
+	}
+	name += "-" + header.ExportedAt.UTC().Format("20060102T150405Z") + exportExtensions[format]
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, exportContentTypes[format])
+	if compress {
+		name += ".gz"
+		res.Header().Set(echo.HeaderContentType, "application/gzip")
+	}
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+name+`"`)
+	res.WriteHeader(http.StatusOK)
+
+	var w io.Writer = res
+	if compress {
+		gz := gzip.NewWriter(res)
+		defer gz.Close()
+		w = gz
+	}
+	// the response is already committed, errors can only be logged
+	if export != nil {
+		err = export.Write(w, header)
+	} else {
+		var entries []cayman.LogEntry
+		if p.files != nil {
+			entries = p.files.entries(filter)
+		}
+		err = journal.WriteEntries(w, entries, format, header)
+	}
+	if err != nil {
+		slog.Error("log export failed", "error", err)
+	}
 	return nil
 }
+
+// exportHeader returns the header of an export made with the query params,
+// identifying the host it was made on.
+func exportHeader(ctx context.Context, query string) cayman.LogExportHeader {
+	header := cayman.LogExportHeader{
+		ExportedAt: time.Now(),
+		Query:      query,
+	}
+	host, err := system.HostInfo()
+	if err != nil {
+		slog.Error("failed to get host info", "error", err)
+		header.Hostname, _ = os.Hostname()
+		return header
+	}
+	header.HostInfo = host.Info()
+	header.Hostname = header.HostInfo.Hostname
+	if header.FQDN, err = host.FQDNWithContext(ctx); err != nil {
+		slog.Debug("failed to get FQDN", "error", err)
+	}
+	return header
+}
@@ -0,0 +1,358 @@
+package processes
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+	"cayman/internal/audit"
+	"cayman/internal/auth"
+	"cayman/internal/data/process"
+	"cayman/internal/redact"
+	syssse "cayman/internal/sse"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tmaxmax/go-sse"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// compile time check for Module interface
+	_         cayman.Module = (*ProcessesModule)(nil)
+	pModule   *ProcessesModule
+	topicHost = "processes"
+)
+
+// topN is the number of processes, by CPU usage, published on each tick.
+const topN = 25
+
+func init() {
+	pModule = &ProcessesModule{}
+	cayman.RegisterModule(pModule)
+}
+
+type ProcessesModule struct {
+	ctx       context.Context
+	sse       *sse.Server
+	collector *process.Collector
+	mu        sync.RWMutex
+	procs     []cayman.ProcessInfo
+}
+
+func (p *ProcessesModule) ShouldEnable() bool {
+	return true
+}
+
+func (p *ProcessesModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Group) {
+	p.ctx = ctx
+	p.sse = syssse.NewSSE(topicHost)
+	p.collector = process.NewCollector()
+	p.refresh()
+	routeGroup := parentRoute.Group("/processes")
+	go p.Poll()
+	routeGroup.GET("/events", echo.WrapHandler(p.sse))
+	routeGroup.GET("/current", p.processListHandler)
+	routeGroup.GET("/:pid", p.processDetailHandler)
+	routeGroup.POST("/:pid/signal", p.signalHandler, auth.RequireAdmin)
+	routeGroup.POST("/:pid/nice", p.niceHandler, auth.RequireAdmin)
+	routeGroup.POST("/:pid/ionice", p.ioniceHandler, auth.RequireAdmin)
+	routeGroup.POST("/:pid/affinity", p.affinityHandler, auth.RequireAdmin)
+}
+
+func (p *ProcessesModule) Topics() []string {
+	return []string{"processes"}
+}
+
+func (p *ProcessesModule) Name() string {
+	return "Processes"
+}
+
+func (p *ProcessesModule) Poll() {
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			procs := p.refresh()
+			top := sortProcesses(slices.Clone(procs), "cpu", true)
+			if len(top) > topN {
+				top = top[:topN]
+			}
+			// every client gets the event, viewers included
+			redactCommands(top)
+			bb, err := json.Marshal(top)
+			if err != nil {
+				slog.Error("processes marshal error", "error", err)
+				continue
+			}
+			event := &sse.Message{
+				Type: sse.Type("top"),
+			}
+			event.AppendData(string(bb))
+			_ = p.sse.Publish(event, topicHost)
+		}
+	}
+}
+
+func (p *ProcessesModule) refresh() []cayman.ProcessInfo {
+	procs, err := p.collector.Collect(p.ctx)
+	if err != nil {
+		slog.Error("processes poll error", "error", err)
+		return nil
+	}
+	p.mu.Lock()
+	p.procs = procs
+	p.mu.Unlock()
+	return procs
+}
+
+// processListHandler returns the process list from the latest sample.
+// Secrets in command lines are masked for viewers, before filtering so the
+// filter cannot probe them.
+//
+// Query params:
+//   - sort: cpu (default), rss, pid, threads, start, user, name, state
+//   - order: desc (default) or asc
+//   - filter: case-insensitive substring of name, command, user or unit
+//   - user, state, unit: exact match
+//   - limit: maximum number of rows
+func (p *ProcessesModule) processListHandler(c echo.Context) error {
+	p.mu.RLock()
+	procs := slices.Clone(p.procs)
+	p.mu.RUnlock()
+	if !auth.IsAdmin(c) {
+		redactCommands(procs)
+	}
+
+	procs = filterProcesses(procs, c.QueryParam("filter"), c.QueryParam("user"), c.QueryParam("state"), c.QueryParam("unit"))
+	procs = sortProcesses(procs, c.QueryParam("sort"), c.QueryParam("order") != "asc")
+	if limit, err := strconv.Atoi(c.QueryParam("limit")); err == nil && limit >= 0 && limit < len(procs) {
+		procs = procs[:limit]
+	}
+	return c.JSON(http.StatusOK, procs)
+}
+
+func (p *ProcessesModule) processDetailHandler(c echo.Context) error {
+	pid, err := pidParam(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pid"})
+	}
+	detail, err := p.collector.Detail(c.Request().Context(), pid, auth.IsAdmin(c))
+	if errors.Is(err, process.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !auth.IsAdmin(c) {
+		detail.Process.Command = redact.Text(detail.Process.Command)
+		redactCommands(detail.Children)
+	}
+	return c.JSON(http.StatusOK, detail)
+}
+
+// redactCommands masks secrets, such as --password=..., in the command lines
+// of procs in place.
+func redactCommands(procs []cayman.ProcessInfo) {
+	for i := range procs {
+		procs[i].Command = redact.Text(procs[i].Command)
+	}
+}
+
+type signalRequest struct {
+	Signal string `json:"signal"` // name (TERM, SIGKILL, hup) or number
+}
+
+type niceRequest struct {
+	Nice int `json:"nice"`
+}
+
+type ioniceRequest struct {
+	Class string `json:"class"`
+	Level int    `json:"level"`
+}
+
+type affinityRequest struct {
+	CPUs []int `json:"cpus"`
+}
+
+func (p *ProcessesModule) signalHandler(c echo.Context) error {
+	pid, err := pidParam(c)
+	if err != nil {
+		return rejected(c, "signal", err)
+	}
+	var req signalRequest
+	if err := c.Bind(&req); err != nil {
+		return rejected(c, "signal", err)
+	}
+	params := map[string]string{"signal": req.Signal}
+	sig, err := process.ParseSignal(req.Signal)
+	if err == nil {
+		err = process.Signal(pid, sig)
+	}
+	if err == nil {
+		// give the process a moment to act on the signal so the
+		// result reflects whether it exited
+		for i := 0; i < 10 && process.Running(pid); i++ {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return p.actionResult(c, pid, "signal", params, err)
+}
+
+func (p *ProcessesModule) niceHandler(c echo.Context) error {
+	pid, err := pidParam(c)
+	if err != nil {
+		return rejected(c, "nice", err)
+	}
+	var req niceRequest
+	if err := c.Bind(&req); err != nil {
+		return rejected(c, "nice", err)
+	}
+	params := map[string]string{"nice": strconv.Itoa(req.Nice)}
+	return p.actionResult(c, pid, "nice", params, process.SetNice(pid, req.Nice))
+}
+
+func (p *ProcessesModule) ioniceHandler(c echo.Context) error {
+	pid, err := pidParam(c)
+	if err != nil {
+		return rejected(c, "ionice", err)
+	}
+	var req ioniceRequest
+	if err := c.Bind(&req); err != nil {
+		return rejected(c, "ionice", err)
+	}
+	params := map[string]string{"class": req.Class, "level": strconv.Itoa(req.Level)}
+	return p.actionResult(c, pid, "ionice", params, process.SetIOPriority(pid, req.Class, req.Level))
+}
+
+func (p *ProcessesModule) affinityHandler(c echo.Context) error {
+	pid, err := pidParam(c)
+	if err != nil {
+		return rejected(c, "affinity", err)
+	}
+	var req affinityRequest
+	if err := c.Bind(&req); err != nil {
+		return rejected(c, "affinity", err)
+	}
+	cpus := make([]string, 0, len(req.CPUs))
+	for _, cpu := range req.CPUs {
+		cpus = append(cpus, strconv.Itoa(cpu))
+	}
+	params := map[string]string{"cpus": strings.Join(cpus, ",")}
+	return p.actionResult(c, pid, "affinity", params, process.SetAffinity(pid, req.CPUs))
+}
+
+// actionResult records an action in the audit trail and responds with the
+// resulting process state, or with the error mapped to a status code.
+func (p *ProcessesModule) actionResult(c echo.Context, pid int32, action string, params map[string]string, err error) error {
+	audit.Record(c, "process."+action, strconv.Itoa(int(pid)), params, err)
+	if err != nil {
+		var errno unix.Errno
+		status := http.StatusBadRequest
+		if errors.As(err, &errno) {
+			switch errno {
+			case unix.ESRCH:
+				status = http.StatusNotFound
+			case unix.EPERM, unix.EACCES:
+				status = http.StatusForbidden
+			case unix.EINVAL:
+				status = http.StatusBadRequest
+			default:
+				status = http.StatusInternalServerError
+			}
+		}
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	result := cayman.ProcessActionResult{
+		PID:     pid,
+		Action:  action,
+		Running: process.Running(pid),
+	}
+	if result.Running {
+		if sched, err := process.Scheduling(pid); err == nil {
+			result.Scheduling = &sched
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// rejected records an action whose request could not be parsed in the audit
+// trail and responds with the error.
+func rejected(c echo.Context, action string, err error) error {
+	audit.Record(c, "process."+action, c.Param("pid"), nil, err)
+	return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+}
+
+func pidParam(c echo.Context) (int32, error) {
+	pid, err := strconv.ParseInt(c.Param("pid"), 10, 32)
+	if err != nil || pid <= 0 {
+		return 0, errors.New("invalid pid")
+	}
+	return int32(pid), nil
+}
+
+func filterProcesses(procs []cayman.ProcessInfo, filter, user, state, unit string) []cayman.ProcessInfo {
+	filter = strings.ToLower(filter)
+	return slices.DeleteFunc(procs, func(pi cayman.ProcessInfo) bool {
+		if user != "" && pi.User != user {
+			return true
+		}
+		if state != "" && pi.State != state {
+			return true
+		}
+		if unit != "" && pi.Unit != unit {
+			return true
+		}
+		if filter == "" {
+			return false
+		}
+		for _, s := range []string{pi.Name, pi.Command, pi.User, pi.Unit} {
+			if strings.Contains(strings.ToLower(s), filter) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func sortProcesses(procs []cayman.ProcessInfo, key string, desc bool) []cayman.ProcessInfo {
+	var by func(a, b cayman.ProcessInfo) int
+	switch key {
+	case "rss":
+		by = func(a, b cayman.ProcessInfo) int { return cmp.Compare(a.RSS, b.RSS) }
+	case "pid":
+		by = func(a, b cayman.ProcessInfo) int { return cmp.Compare(a.PID, b.PID) }
+	case "threads":
+		by = func(a, b cayman.ProcessInfo) int { return cmp.Compare(a.Threads, b.Threads) }
+	case "start":
+		by = func(a, b cayman.ProcessInfo) int { return a.StartTime.Compare(b.StartTime) }
+	case "user":
+		by = func(a, b cayman.ProcessInfo) int { return strings.Compare(a.User, b.User) }
+	case "name":
+		by = func(a, b cayman.ProcessInfo) int { return strings.Compare(a.Name, b.Name) }
+	case "state":
+		by = func(a, b cayman.ProcessInfo) int { return strings.Compare(a.State, b.State) }
+	default:
+		by = func(a, b cayman.ProcessInfo) int { return cmp.Compare(a.CPUPercent, b.CPUPercent) }
+	}
+	slices.SortStableFunc(procs, func(a, b cayman.ProcessInfo) int {
+		if desc {
+			return by(b, a)
+		}
+		return by(a, b)
+	})
+	return procs
+}
@@ -2,8 +2,11 @@ package system
 
 import (
 	"context"
+	"net/http"
 
 	"cayman"
+	"cayman/internal/audit"
+	"cayman/internal/auth"
 	syssse "cayman/internal/sse"
 
 	"github.com/labstack/echo/v4"
@@ -40,6 +43,7 @@ func (p *SystemModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Gro
 	go p.Poll()
 	routeGroup.GET("/events", echo.WrapHandler(p.sse))
 	routeGroup.GET("/current", p.systemInfoHandler)
+	routeGroup.GET("/audit", p.auditHandler, auth.RequireAdmin)
 }
 
 func (p *SystemModule) Topics() []string {
@@ -58,3 +62,8 @@ func (p *SystemModule) systemInfoHandler(c echo.Context) error {
 	// Logic to handle system info requests
 	return nil
 }
+
+// auditHandler returns the in-memory audit trail of privileged actions.
+func (p *SystemModule) auditHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, audit.Entries())
+}
@@ -0,0 +1,438 @@
+package systemd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cayman"
+	"cayman/internal/audit"
+	"cayman/internal/auth"
+	"cayman/internal/data/journal"
+	sd "cayman/internal/data/systemd"
+	syssse "cayman/internal/sse"
+	sysevents "cayman/internal/system"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tmaxmax/go-sse"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// compile time check for Module interface
+	_         cayman.Module = (*SystemdModule)(nil)
+	sModule   *SystemdModule
+	topicHost = "systemd"
+)
+
+// resyncInterval is how often the full unit list is reloaded. Changes are
+// normally received as D-Bus signals; the resync picks up units that were
+// unloaded, which systemd does not report as a property change.
+const resyncInterval = 5 * time.Minute
+
+// actionTimeout bounds how long an action waits for its job to complete.
+const actionTimeout = 90 * time.Second
+
+// failureJournalLines is the number of journal lines included in the warning
+// raised when a unit fails.
+const failureJournalLines = 10
+
+// scopes are the managers the module connects to. The user manager is
+// optional, it is only reachable when a session bus is available.
+var scopes = []cayman.UnitScope{cayman.UnitScopeSystem, cayman.UnitScopeUser}
+
+func init() {
+	sModule = &SystemdModule{}
+	cayman.RegisterModule(sModule)
+}
+
+type SystemdModule struct {
+	ctx context.Context
+	sse *sse.Server
+	// managers is filled once by RegisterRoutes and read-only afterwards.
+	managers map[cayman.UnitScope]*sd.Manager
+	mu       sync.RWMutex
+	units    map[cayman.UnitScope]map[string]cayman.Unit
+}
+
+func (p *SystemdModule) ShouldEnable() bool {
+	for _, scope := range scopes {
+		manager, err := connect(context.Background(), scope)
+		if err != nil {
+			logUnavailable("failed to connect to systemd", scope, err)
+			continue
+		}
+		manager.Close()
+		return true
+	}
+	return false
+}
+
+func (p *SystemdModule) RegisterRoutes(ctx context.Context, parentRoute *echo.Group) {
+	p.ctx = ctx
+	p.sse = syssse.NewSSE(topicHost)
+	p.managers = make(map[cayman.UnitScope]*sd.Manager)
+	p.units = make(map[cayman.UnitScope]map[string]cayman.Unit)
+	for _, scope := range scopes {
+		manager, err := connect(ctx, scope)
+		if err != nil {
+			logUnavailable("failed to connect to systemd", scope, err)
+			continue
+		}
+		p.managers[scope] = manager
+		p.units[scope] = make(map[string]cayman.Unit)
+		p.resync(manager)
+		go p.Poll(manager)
+	}
+	routeGroup := parentRoute.Group("/systemd")
+	routeGroup.GET("/events", echo.WrapHandler(p.sse))
+	routeGroup.GET("/units", p.unitsHandler)
+	routeGroup.GET("/units/:name", p.unitDetailHandler)
+	routeGroup.GET("/timers", p.timersHandler)
+	routeGroup.PUT("/units/:name/dropin", p.dropInHandler, auth.RequireAdmin)
+	routeGroup.POST("/units/:name/:action", p.unitActionHandler, auth.RequireAdmin)
+}
+
+func (p *SystemdModule) Topics() []string {
+	return []string{"systemd"}
+}
+
+func (p *SystemdModule) Name() string {
+	return "Systemd"
+}
+
+// Poll publishes a unit event whenever the manager reports a property
+// change.
+func (p *SystemdModule) Poll(manager *sd.Manager) {
+	defer manager.Close()
+
+	changed, errs, err := manager.Watch(p.ctx)
+	if err != nil {
+		logUnavailable("failed to subscribe to systemd", manager.Scope(), err)
+		return
+	}
+	ticker := time.NewTicker(resyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			p.resync(manager)
+		case err := <-errs:
+			slog.Error("systemd subscription error", "scope", manager.Scope(), "error", err)
+		case name, ok := <-changed:
+			if !ok {
+				return
+			}
+			_, _ = p.update(manager, name)
+		}
+	}
+}
+
+func (p *SystemdModule) resync(manager *sd.Manager) {
+	units, err := manager.ListUnits(p.ctx)
+	if err != nil {
+		slog.Error("failed to list units", "scope", manager.Scope(), "error", err)
+		return
+	}
+	m := make(map[string]cayman.Unit, len(units))
+	for _, u := range units {
+		m[u.Name] = u
+	}
+	p.mu.Lock()
+	p.units[manager.Scope()] = m
+	p.mu.Unlock()
+}
+
+// update refreshes a unit and publishes its new state.
+func (p *SystemdModule) update(manager *sd.Manager, name string) (cayman.Unit, error) {
+	unit, err := manager.Unit(p.ctx, name)
+	if err != nil {
+		slog.Error("failed to get unit", "scope", manager.Scope(), "unit", name, "error", err)
+		return unit, err
+	}
+	p.mu.Lock()
+	prev, known := p.units[manager.Scope()][name]
+	p.units[manager.Scope()][name] = unit
+	p.mu.Unlock()
+
+	if unit.ActiveState == "failed" && (!known || prev.ActiveState != "failed") {
+		go p.notifyFailure(manager, name)
+	}
+
+	bb, err := json.Marshal(unit)
+	if err != nil {
+		slog.Error("systemd marshal error", "error", err)
+		return unit, nil
+	}
+	event := &sse.Message{
+		Type: sse.Type("unit"),
+	}
+	event.AppendData(string(bb))
+	_ = p.sse.Publish(event, topicHost)
+	return unit, nil
+}
+
+// managerFor returns the manager selected by the scope query param, the
+// system manager by default.
+func (p *SystemdModule) managerFor(c echo.Context) (*sd.Manager, error) {
+	scope, err := sd.ParseScope(c.QueryParam("scope"))
+	if err != nil {
+		return nil, err
+	}
+	manager, ok := p.managers[scope]
+	if !ok {
+		return nil, fmt.Errorf("%s manager is not available", scope)
+	}
+	return manager, nil
+}
+
+// connect connects to the manager of scope. A session bus may be there
+// without a user manager behind it, so for the user scope the manager must
+// also answer.
+func connect(ctx context.Context, scope cayman.UnitScope) (*sd.Manager, error) {
+	manager, err := sd.NewManager(ctx, scope)
+	if err != nil || scope != cayman.UnitScopeUser {
+		return manager, err
+	}
+	if _, err := manager.ListUnits(ctx); err != nil {
+		manager.Close()
+		return nil, err
+	}
+	return manager, nil
+}
+
+// logUnavailable logs a failure to reach the manager of scope. Without a
+// session bus, the usual case for a system service, the user manager is
+// missing by design, so that is only logged at info.
+func logUnavailable(msg string, scope cayman.UnitScope, err error) {
+	level := slog.LevelError
+	if scope == cayman.UnitScopeUser {
+		level = slog.LevelInfo
+	}
+	slog.Log(context.Background(), level, msg, "scope", scope, "error", err)
+}
+
+// notifyFailure raises a system warning for a unit that entered the failed
+// state, with the cause and its most recent journal lines.
+func (p *SystemdModule) notifyFailure(manager *sd.Manager, name string) {
+	var msg strings.Builder
+	if manager.Scope() == cayman.UnitScopeUser {
+		fmt.Fprintf(&msg, "user unit %s failed", name)
+	} else {
+		fmt.Fprintf(&msg, "unit %s failed", name)
+	}
+	cause, err := manager.FailureCause(p.ctx, name)
+	if err != nil {
+		slog.Error("failed to get unit failure cause", "unit", name, "error", err)
+	} else {
+		fmt.Fprintf(&msg, ": result %s", cause.Result)
+		switch cause.ExitCode {
+		case "exited":
+			fmt.Fprintf(&msg, ", exit code %d", cause.ExitStatus)
+		case "killed", "dumped":
+			fmt.Fprintf(&msg, ", %s by signal %s", cause.ExitCode, unix.SignalName(unix.Signal(cause.ExitStatus)))
+		}
+	}
+	lines, err := journal.UnitTail(p.ctx, manager.Scope(), name, failureJournalLines)
+	if err != nil {
+		slog.Error("failed to read unit journal", "unit", name, "error", err)
+	}
+	for _, line := range lines {
+		msg.WriteString("\n")
+		msg.WriteString(line)
+	}
+	if err := sysevents.PublishSystemEvent(sysevents.SystemEventTypeWarning, msg.String()); err != nil {
+		slog.Error("failed to publish unit failure", "unit", name, "error", err)
+	}
+}
+
+// unitsHandler lists units sorted by name, system units first.
+//
+// Query params:
+//   - scope: system or user, both when omitted
+//   - type: unit type, e.g. service or timer
+//   - state: matches the load, active, sub or enabled state
+//   - pattern: shell glob on the unit name, e.g. ssh*
+func (p *SystemdModule) unitsHandler(c echo.Context) error {
+	unitType := c.QueryParam("type")
+	state := c.QueryParam("state")
+	pattern := c.QueryParam("pattern")
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid pattern"})
+		}
+	}
+
+	var scope cayman.UnitScope
+	if c.QueryParam("scope") != "" {
+		var err error
+		if scope, err = sd.ParseScope(c.QueryParam("scope")); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	p.mu.RLock()
+	units := make([]cayman.Unit, 0)
+	for s, uu := range p.units {
+		if scope != "" && s != scope {
+			continue
+		}
+		for _, u := range uu {
+			if matches(u, unitType, state, pattern) {
+				units = append(units, u)
+			}
+		}
+	}
+	p.mu.RUnlock()
+
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].Scope != units[j].Scope {
+			return units[i].Scope == cayman.UnitScopeSystem
+		}
+		return units[i].Name < units[j].Name
+	})
+	return c.JSON(http.StatusOK, units)
+}
+
+// matches reports whether a unit passes the filters of unitsHandler, where
+// empty filters match everything.
+func matches(u cayman.Unit, unitType, state, pattern string) bool {
+	if unitType != "" && u.Type != unitType {
+		return false
+	}
+	if state != "" && u.LoadState != state && u.ActiveState != state && u.SubState != state && u.EnabledState != state {
+		return false
+	}
+	if pattern != "" {
+		if ok, _ := path.Match(pattern, u.Name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// unitActionHandler runs start, stop, restart or reload as a job and waits
+// for its result, or enables, disables, masks or unmasks the unit file.
+func (p *SystemdModule) unitActionHandler(c echo.Context) error {
+	name := c.Param("name")
+	action := c.Param("action")
+	manager, err := p.managerFor(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	ctx, cancel := context.WithTimeout(c.Request().Context(), actionTimeout)
+	defer cancel()
+
+	result := cayman.UnitActionResult{
+		Unit:   name,
+		Action: action,
+	}
+	switch {
+	case slices.Contains(sd.JobActions, action):
+		result.JobResult, err = manager.RunJob(ctx, action, name)
+	case slices.Contains(sd.FileActions, action):
+		result.Changes, err = manager.ChangeUnitFile(ctx, action, name)
+	default:
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown action " + action})
+	}
+	audit.Record(c, "unit."+action, name, map[string]string{"scope": string(manager.Scope())}, err)
+
+	// publish the new state even on failure, a failed start changes it too
+	state, uerr := p.update(manager, name)
+	if err == nil {
+		err = uerr
+	}
+	result.State = state
+	if err != nil {
+		result.Error = err.Error()
+		return c.JSON(http.StatusInternalServerError, result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (p *SystemdModule) unitDetailHandler(c echo.Context) error {
+	manager, err := p.managerFor(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	detail, err := manager.Detail(c.Request().Context(), c.Param("name"), !auth.IsAdmin(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, detail)
+}
+
+// timersHandler lists timers sorted by their next calendar trigger, timers
+// without one last. Timers of both managers are listed unless the scope
+// query param selects one.
+func (p *SystemdModule) timersHandler(c echo.Context) error {
+	managers := make([]*sd.Manager, 0, len(p.managers))
+	if c.QueryParam("scope") != "" {
+		manager, err := p.managerFor(c)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		managers = append(managers, manager)
+	} else {
+		for _, scope := range scopes {
+			if manager, ok := p.managers[scope]; ok {
+				managers = append(managers, manager)
+			}
+		}
+	}
+	timers := make([]cayman.Timer, 0)
+	for _, manager := range managers {
+		tt, err := manager.ListTimers(c.Request().Context())
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		timers = append(timers, tt...)
+	}
+	sort.SliceStable(timers, func(i, j int) bool {
+		a, b := timers[i].NextElapseRealtime, timers[j].NextElapseRealtime
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+	return c.JSON(http.StatusOK, timers)
+}
+
+type dropInRequest struct {
+	Name    string `json:"name"`    // drop-in file name, defaults to override
+	Content string `json:"content"` // empty removes the drop-in
+}
+
+// dropInHandler writes a drop-in override for a unit, reloads the manager
+// and responds with the updated unit detail.
+func (p *SystemdModule) dropInHandler(c echo.Context) error {
+	name := c.Param("name")
+	manager, err := p.managerFor(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	var req dropInRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if req.Name == "" {
+		req.Name = "override"
+	}
+	file, err := manager.WriteDropIn(c.Request().Context(), name, req.Name, req.Content)
+	audit.Record(c, "unit.dropin", name, map[string]string{"scope": string(manager.Scope()), "file": file}, err)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	_, _ = p.update(manager, name)
+	return p.unitDetailHandler(c)
+}
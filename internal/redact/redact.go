@@ -0,0 +1,118 @@
+// Package redact masks values that look like secrets, such as passwords in
+// environment variables, command line flags and labels, before they are
+// shown to viewers.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask replaces the value of a secret.
+const Mask = "********"
+
+// secretKey matches the names of variables, flags and labels that usually
+// hold secrets, e.g. POSTGRES_PASSWORD, --api-key or
+// traefik.http.middlewares.x.basicauth.users.
+var secretKey = regexp.MustCompile(`(?i)pass|secret|token|api_?-?key|access_?-?key|private_?-?key|credential|auth|cookie|session|salt|dsn`)
+
+// secretURL matches values embedding a password in a URL, e.g.
+// postgres://app:hunter2@db/app.
+var secretURL = regexp.MustCompile(`://[^/\s:@]*:[^/\s@]+@`)
+
+// IsSecret reports whether a value looks like a secret, by its key or by
+// holding a URL with a password.
+func IsSecret(key, value string) bool {
+	return value != "" && (secretKey.MatchString(key) || secretURL.MatchString(value))
+}
+
+// Env returns env, a list of KEY=value pairs, with secret values masked.
+func Env(env []string) []string {
+	masked := make([]string, len(env))
+	for i, kv := range env {
+		if key, value, ok := strings.Cut(kv, "="); ok && IsSecret(key, value) {
+			kv = key + "=" + Mask
+		}
+		masked[i] = kv
+	}
+	return masked
+}
+
+// Args returns a command line with secret values masked: those of
+// secret-looking --flag=value and KEY=value arguments, the argument
+// following a secret-looking --flag, and URLs with a password.
+func Args(args []string) []string {
+	masked := make([]string, len(args))
+	copy(masked, args)
+	for i := 0; i < len(masked); i++ {
+		arg := masked[i]
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			if IsSecret(key, value) {
+				masked[i] = key + "=" + Mask
+			}
+			continue
+		}
+		if secretURL.MatchString(arg) {
+			masked[i] = Mask
+			continue
+		}
+		// --password hunter2
+		if strings.HasPrefix(arg, "-") && secretKey.MatchString(arg) &&
+			i+1 < len(masked) && !strings.HasPrefix(masked[i+1], "-") {
+			masked[i+1] = Mask
+			i++
+		}
+	}
+	return masked
+}
+
+// Labels returns labels with secret values masked.
+func Labels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(labels))
+	for key, value := range labels {
+		if IsSecret(key, value) {
+			value = Mask
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// assignment matches KEY=value and --flag=value pairs in free text, with
+// optionally quoted values, or quoted as a whole as systemd allows.
+var assignment = regexp.MustCompile(`"[\w.-]+=[^"]*"|'[\w.-]+=[^']*'|[\w.-]+=(?:"[^"]*"|'[^']*'|[^\s"']+)`)
+
+// flagValue matches a --flag followed by its value as the next word.
+var flagValue = regexp.MustCompile(`(^|\s)(--?[\w.-]+)(\s+)([^\s-]\S*)`)
+
+// Text returns a line of free text, such as the value of a unit file
+// setting, with secret values masked the way Env and Args mask them. The
+// rest of the text is kept as it is.
+func Text(s string) string {
+	s = assignment.ReplaceAllStringFunc(s, func(m string) string {
+		var quote string
+		if m[0] == '"' || m[0] == '\'' {
+			quote, m = m[:1], m[1:len(m)-1]
+		}
+		key, value, _ := strings.Cut(m, "=")
+		if !IsSecret(key, strings.Trim(value, `"'`)) {
+			return quote + m + quote
+		}
+		if value != "" && (value[0] == '"' || value[0] == '\'') {
+			// keep the quotes of the value
+			return key + "=" + value[:1] + Mask + value[:1]
+		}
+		return quote + key + "=" + Mask + quote
+	})
+	s = flagValue.ReplaceAllStringFunc(s, func(m string) string {
+		sub := flagValue.FindStringSubmatch(m)
+		if !secretKey.MatchString(sub[2]) {
+			return m
+		}
+		return sub[1] + sub[2] + sub[3] + Mask
+	})
+	return secretURL.ReplaceAllString(s, "://"+Mask+"@")
+}
@@ -1,4 +1,6 @@
-package modules
+// Package ringbuffer provides a fixed-size buffer keeping the most recent
+// elements added to it.
+package ringbuffer
 
 import (
 	"sync"
@@ -12,8 +14,8 @@ type RingBuffer[T any] struct {
 	count  int
 }
 
-// NewRingBuffer creates a new ring buffer with a fixed size.
-func NewRingBuffer[T any](size int) *RingBuffer[T] {
+// New creates a new ring buffer with a fixed size.
+func New[T any](size int) *RingBuffer[T] {
 	return &RingBuffer[T]{
 		buffer: make([]T, size),
 		size:   size,
@@ -0,0 +1,15 @@
+package cayman
+
+import "time"
+
+// AuditEntry records a privileged action performed through the API.
+type AuditEntry struct {
+	Time       time.Time         `json:"time"`
+	Role       string            `json:"role"`
+	RemoteAddr string            `json:"remote_addr"`
+	Action     string            `json:"action"` // e.g. process.signal
+	Target     string            `json:"target"` // e.g. the PID or unit name
+	Params     map[string]string `json:"params,omitempty"`
+	Success    bool              `json:"success"`
+	Error      string            `json:"error,omitempty"`
+}
@@ -1,6 +1,10 @@
 package cayman
 
-import "github.com/elastic/go-sysinfo/types"
+import (
+	"time"
+
+	"github.com/elastic/go-sysinfo/types"
+)
 
 // HostState is used on the dashboard
 type HostState struct {
@@ -15,6 +19,21 @@ type HostState struct {
 	LogicalCores  int                  `json:"logical_cores"`  // Include logical cores
 	HostInfo      types.HostInfo       `json:"host_info"`      // Include host info
 	MemoryInfo    types.HostMemoryInfo `json:"memory_info"`    // Include memory info
+	Network       []NetInterface       `json:"network"`        // Include network interfaces
+	DiskIO        []DiskIO             `json:"disk_io"`        // Include block device activity
+	Pressure      PressureInfo         `json:"pressure"`       // Include pressure stall information
+	Sensors       SensorsInfo          `json:"sensors"`        // Include hardware sensors
+	Memory        MemoryDetail         `json:"memory"`         // Include memory breakdown
+}
+
+// MetricsSample is one entry of the dashboard metrics history.
+type MetricsSample struct {
+	Time    time.Time    `json:"time"`
+	CPU     int          `json:"cpu"`
+	Load    Load         `json:"load"`
+	DiskIO  []DiskIO     `json:"disk_io"`
+	Sensors []Sensor     `json:"sensors"`
+	Memory  MemoryDetail `json:"memory"`
 }
 
 type UnitStatus struct {
@@ -0,0 +1,12 @@
+package cayman
+
+// DiskIO is the I/O activity of a block device since the previous sample.
+type DiskIO struct {
+	Name             string  `json:"name"`
+	ReadBytesPerSec  float64 `json:"read_bytes_per_sec"`
+	WriteBytesPerSec float64 `json:"write_bytes_per_sec"`
+	ReadIOPS         float64 `json:"read_iops"`
+	WriteIOPS        float64 `json:"write_iops"`
+	AwaitMs          float64 `json:"await_ms"`    // average time per completed request
+	Utilization      float64 `json:"utilization"` // percent of time the device was busy
+}
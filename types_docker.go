@@ -1,6 +1,8 @@
 package cayman
 
 import (
+	"time"
+
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 )
@@ -9,3 +11,62 @@ type DockerInfo struct {
 	Containers []container.Summary `json:"containers"`
 	Images     []image.Summary     `json:"images"`
 }
+
+// ContainerActionResult confirms the outcome of an action on a container.
+type ContainerActionResult struct {
+	ID     string           `json:"id"`
+	Action string           `json:"action"`
+	State  *container.State `json:"state,omitempty"` // nil once the container is removed
+	Error  string           `json:"error,omitempty"`
+}
+
+// ContainerEvent reports a change to a container, from a daemon event or an
+// action.
+type ContainerEvent struct {
+	ID     string `json:"id"`
+	Action string `json:"action"` // the daemon's event action, e.g. start or die
+	// Container is the new summary, nil once the container is removed.
+	Container *container.Summary `json:"container,omitempty"`
+}
+
+// ContainerLogLine is a line written by a container to its standard output
+// or error.
+type ContainerLogLine struct {
+	Stream string `json:"stream"` // stdout or stderr
+	// Time is when the daemon received the line, only set when timestamps
+	// are requested.
+	Time    *time.Time `json:"time,omitempty"`
+	Message string     `json:"message"`
+}
+
+// TerminalMessage is a control message of an exec terminal, sent as a
+// WebSocket text message. Terminal output is sent as binary messages.
+type TerminalMessage struct {
+	Type string `json:"type"`           // stdin or resize from the client, exit or error from the server
+	Data string `json:"data,omitempty"` // stdin: keystrokes to send, error: the error
+	Cols uint   `json:"cols,omitempty"` // resize: terminal width
+	Rows uint   `json:"rows,omitempty"` // resize: terminal height
+	// ExitCode is the exit code of the command, for exit; -1 when it is
+	// unknown.
+	ExitCode int `json:"exit_code"`
+}
+
+// ContainerStats is a resource usage sample of a running container. Byte
+// counters are totals since the container started; rates are per second
+// since the previous sample.
+type ContainerStats struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Time           time.Time `json:"time"`
+	CPUPercent     float64   `json:"cpu_percent"`  // 100 is one full CPU
+	MemoryUsage    uint64    `json:"memory_usage"` // excluding inactive page cache, as docker stats
+	MemoryLimit    uint64    `json:"memory_limit"`
+	NetRx          uint64    `json:"net_rx"`
+	NetTx          uint64    `json:"net_tx"`
+	NetRxRate      float64   `json:"net_rx_rate"`
+	NetTxRate      float64   `json:"net_tx_rate"`
+	BlockRead      uint64    `json:"block_read"`
+	BlockWrite     uint64    `json:"block_write"`
+	BlockReadRate  float64   `json:"block_read_rate"`
+	BlockWriteRate float64   `json:"block_write_rate"`
+}
@@ -0,0 +1,60 @@
+package cayman
+
+import (
+	"time"
+
+	"github.com/elastic/go-sysinfo/types"
+)
+
+// LogEntry is a single log record, decoded from the journal's well known
+// fields.
+type LogEntry struct {
+	// Cursor identifies the entry in the journal and is used to page from it.
+	Cursor     string    `json:"cursor"`
+	Time       time.Time `json:"time"`
+	Priority   int       `json:"priority"`           // syslog level, 0 (emerg) to 7 (debug)
+	Facility   string    `json:"facility,omitempty"` // syslog facility, e.g. kern or daemon
+	Hostname   string    `json:"hostname"`
+	Identifier string    `json:"identifier"` // SYSLOG_IDENTIFIER, e.g. sshd
+	PID        int32     `json:"pid"`
+	Unit       string    `json:"unit,omitempty"`      // system unit
+	UserUnit   string    `json:"user_unit,omitempty"` // unit of the user manager
+	BootID     string    `json:"boot_id"`
+	Transport  string    `json:"transport"`           // journal, syslog, stdout, kernel, audit, driver or file
+	Container  string    `json:"container,omitempty"` // set by the docker and podman journald drivers
+	Message    string    `json:"message"`
+	// Source is the path of the file an entry was read from, for the file
+	// transport.
+	Source string `json:"source,omitempty"`
+}
+
+// LogPage is one page of a log query, newest entry first.
+type LogPage struct {
+	Entries []LogEntry `json:"entries"`
+	// Next is the cursor to pass to fetch the following, older page. It is
+	// empty when there are no older entries.
+	Next string `json:"next,omitempty"`
+}
+
+// LogExportHeader precedes the entries of a log export, identifying the host
+// and the filters it was made with.
+type LogExportHeader struct {
+	ExportedAt time.Time      `json:"exported_at"`
+	Query      string         `json:"query"` // filter query params of the export
+	Hostname   string         `json:"hostname"`
+	FQDN       string         `json:"fqdn"`
+	HostInfo   types.HostInfo `json:"host_info"`
+}
+
+// Boot is a boot recorded in the journal.
+type Boot struct {
+	// Index is relative to the current boot: 0 is the current boot, -1 the
+	// one before, and so on.
+	Index      int       `json:"index"`
+	ID         string    `json:"id"`
+	FirstEntry time.Time `json:"first_entry"`
+	LastEntry  time.Time `json:"last_entry"`
+	// Kernel is the kernel release, read from the boot's "Linux version"
+	// message. It is empty when the kernel messages were not kept.
+	Kernel string `json:"kernel,omitempty"`
+}
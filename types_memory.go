@@ -0,0 +1,74 @@
+package cayman
+
+// MemoryDetail is a breakdown of memory usage from /proc/meminfo and
+// /proc/vmstat. All sizes are in bytes.
+type MemoryDetail struct {
+	Total     uint64 `json:"total"`
+	Free      uint64 `json:"free"`
+	Available uint64 `json:"available"`
+
+	Buffers      uint64 `json:"buffers"`
+	Cached       uint64 `json:"cached"` // page cache, excluding swap cache
+	Anon         uint64 `json:"anon"`
+	Shmem        uint64 `json:"shmem"`
+	Slab         uint64 `json:"slab"`
+	SReclaimable uint64 `json:"sreclaimable"`
+	SUnreclaim   uint64 `json:"sunreclaim"`
+	Mapped       uint64 `json:"mapped"`
+	PageTables   uint64 `json:"page_tables"`
+	KernelStack  uint64 `json:"kernel_stack"`
+	Dirty        uint64 `json:"dirty"`
+	Writeback    uint64 `json:"writeback"`
+
+	SwapTotal   uint64       `json:"swap_total"`
+	SwapFree    uint64       `json:"swap_free"`
+	SwapCached  uint64       `json:"swap_cached"`
+	SwapDevices []SwapDevice `json:"swap_devices"`
+
+	HugePages HugePages    `json:"huge_pages"`
+	Zswap     ZswapStats   `json:"zswap"`
+	Zram      []ZramDevice `json:"zram"`
+
+	// cumulative counters since boot
+	SwapIns   uint64 `json:"swap_ins"`  // pages
+	SwapOuts  uint64 `json:"swap_outs"` // pages
+	MajFaults uint64 `json:"major_faults"`
+	OOMKills  uint64 `json:"oom_kills"`
+}
+
+// SwapDevice is an entry of /proc/swaps.
+type SwapDevice struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // partition or file
+	Size     uint64 `json:"size"`
+	Used     uint64 `json:"used"`
+	Priority int    `json:"priority"`
+}
+
+// HugePages describes the explicit (hugetlbfs) huge page pool and
+// transparent huge page usage.
+type HugePages struct {
+	PageSize      uint64 `json:"page_size"`
+	Total         uint64 `json:"total"` // pages
+	Free          uint64 `json:"free"`  // pages
+	Reserved      uint64 `json:"reserved"`
+	Surplus       uint64 `json:"surplus"`
+	AnonHugePages uint64 `json:"anon_huge_pages"` // transparent, bytes
+}
+
+// ZswapStats is the compressed swap cache.
+type ZswapStats struct {
+	Enabled bool   `json:"enabled"`
+	Pool    uint64 `json:"pool"`   // compressed size in memory
+	Stored  uint64 `json:"stored"` // uncompressed size of the stored pages
+}
+
+// ZramDevice is a compressed RAM block device, typically used as swap.
+type ZramDevice struct {
+	Name         string `json:"name"`
+	Algorithm    string `json:"algorithm"`
+	DiskSize     uint64 `json:"disk_size"`
+	OrigDataSize uint64 `json:"orig_data_size"`
+	ComprSize    uint64 `json:"compr_data_size"`
+	MemUsed      uint64 `json:"mem_used_total"`
+}
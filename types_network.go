@@ -0,0 +1,23 @@
+package cayman
+
+// NetInterface describes a network interface and its throughput since the
+// previous sample.
+type NetInterface struct {
+	Name         string   `json:"name"`
+	HardwareAddr string   `json:"hardware_addr"`
+	Addresses    []string `json:"addresses"`  // CIDR notation
+	OperState    string   `json:"oper_state"` // up, down, unknown, ...
+	Speed        int      `json:"speed"`      // Mbit/s, -1 if unknown
+	MTU          int      `json:"mtu"`
+
+	RxBytesPerSec float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec float64 `json:"tx_bytes_per_sec"`
+	RxBytes       uint64  `json:"rx_bytes"`
+	TxBytes       uint64  `json:"tx_bytes"`
+	RxPackets     uint64  `json:"rx_packets"`
+	TxPackets     uint64  `json:"tx_packets"`
+	RxErrors      uint64  `json:"rx_errors"`
+	TxErrors      uint64  `json:"tx_errors"`
+	RxDropped     uint64  `json:"rx_dropped"`
+	TxDropped     uint64  `json:"tx_dropped"`
+}
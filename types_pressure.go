@@ -0,0 +1,32 @@
+package cayman
+
+// PressureStat is one line of a Linux PSI pressure file. Averages are the
+// percentage of time stalled over the window, Total is cumulative stall time
+// in microseconds.
+type PressureStat struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"`
+}
+
+// Pressure holds the "some" (at least one task stalled) and "full" (all
+// non-idle tasks stalled) lines for a resource.
+type Pressure struct {
+	Some PressureStat `json:"some"`
+	Full PressureStat `json:"full"`
+}
+
+// PressureInfo is the Pressure Stall Information for cpu, memory and io.
+type PressureInfo struct {
+	CPU    Pressure `json:"cpu"`
+	Memory Pressure `json:"memory"`
+	IO     Pressure `json:"io"`
+}
+
+// CgroupPressure is the Pressure Stall Information of a single cgroup, such
+// as a systemd service or a container.
+type CgroupPressure struct {
+	Cgroup   string       `json:"cgroup"`
+	Pressure PressureInfo `json:"pressure"`
+}
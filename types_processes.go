@@ -0,0 +1,56 @@
+package cayman
+
+import "time"
+
+// ProcessInfo is a row of the process list.
+type ProcessInfo struct {
+	PID        int32     `json:"pid"`
+	PPID       int32     `json:"ppid"`
+	Name       string    `json:"name"`
+	User       string    `json:"user"`
+	Command    string    `json:"command"`     // full command line
+	CPUPercent float64   `json:"cpu_percent"` // percent of one CPU since the previous sample
+	RSS        uint64    `json:"rss"`         // bytes
+	Threads    int32     `json:"threads"`
+	State      string    `json:"state"` // running, sleep, stop, idle, zombie, wait, lock
+	StartTime  time.Time `json:"start_time"`
+	Cgroup     string    `json:"cgroup"`
+	Unit       string    `json:"unit"` // systemd unit owning the cgroup, if any
+}
+
+// ProcessLimit is one line of /proc/<pid>/limits.
+type ProcessLimit struct {
+	Resource string `json:"resource"`
+	Soft     string `json:"soft"`
+	Hard     string `json:"hard"`
+	Units    string `json:"units"`
+}
+
+// ProcessDetail is the expanded view of a single process. Environment is
+// only populated for admin requests.
+type ProcessDetail struct {
+	Process     ProcessInfo    `json:"process"`
+	Executable  string         `json:"executable"`
+	Cwd         string         `json:"cwd"`
+	OpenFiles   []string       `json:"open_files"`
+	Environment []string       `json:"environment,omitempty"`
+	Limits      []ProcessLimit `json:"limits"`
+	Children    []ProcessInfo  `json:"children"`
+}
+
+// ProcessScheduling is the CPU and I/O scheduling configuration of a process.
+type ProcessScheduling struct {
+	Nice     int    `json:"nice"`
+	IOClass  string `json:"io_class"` // none, realtime, best-effort, idle
+	IOLevel  int    `json:"io_level"` // 0 (highest) to 7
+	Affinity []int  `json:"affinity"` // CPUs the process may run on
+}
+
+// ProcessActionResult confirms the outcome of a signal or scheduling change.
+// Scheduling is omitted once the process has exited.
+type ProcessActionResult struct {
+	PID        int32              `json:"pid"`
+	Action     string             `json:"action"`
+	Running    bool               `json:"running"`
+	Scheduling *ProcessScheduling `json:"scheduling,omitempty"`
+}
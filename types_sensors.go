@@ -0,0 +1,41 @@
+package cayman
+
+// SensorKind is the quantity a Sensor measures.
+type SensorKind string
+
+const (
+	SensorKindTemperature SensorKind = "temperature" // degrees Celsius
+	SensorKindFan         SensorKind = "fan"         // RPM
+	SensorKindPower       SensorKind = "power"       // watts
+)
+
+// Sensor is a single hardware monitoring reading. High, Critical and Low are
+// zero when the driver does not report a threshold.
+type Sensor struct {
+	// ID identifies the sensor by its device and channel; chip and label
+	// repeat across identical devices, e.g. Composite on every NVMe drive.
+	ID       string     `json:"id"`
+	Chip     string     `json:"chip"`
+	Label    string     `json:"label"`
+	Kind     SensorKind `json:"kind"`
+	Value    float64    `json:"value"`
+	High     float64    `json:"high"`
+	Critical float64    `json:"critical"` // temperature and power only
+	Low      float64    `json:"low"`      // fan minimum speed
+	Alarm    bool       `json:"alarm"`    // set by the driver, e.g. a fan below its minimum
+}
+
+// PowerSupply is a battery or AC adapter.
+type PowerSupply struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`     // Battery, Mains, USB, ...
+	Status   string `json:"status"`   // Charging, Discharging, Full, ... (batteries only)
+	Online   bool   `json:"online"`   // adapter connected (mains only)
+	Capacity int    `json:"capacity"` // percent, -1 if unknown
+}
+
+// SensorsInfo groups all hardware sensor readings of the host.
+type SensorsInfo struct {
+	Sensors       []Sensor      `json:"sensors"`
+	PowerSupplies []PowerSupply `json:"power_supplies"`
+}
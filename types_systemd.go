@@ -0,0 +1,83 @@
+package cayman
+
+import "time"
+
+// UnitScope is the systemd manager a unit belongs to.
+type UnitScope string
+
+const (
+	UnitScopeSystem UnitScope = "system" // the system manager, PID 1
+	UnitScopeUser   UnitScope = "user"   // the user manager of the user cayman runs as
+)
+
+// Unit is a systemd unit as listed by the systemd module.
+type Unit struct {
+	Name         string    `json:"name"`
+	Scope        UnitScope `json:"scope"`
+	Description  string    `json:"description"`
+	Type         string    `json:"type"`          // service, socket, timer, ...
+	LoadState    string    `json:"load_state"`    // loaded, not-found, masked, ...
+	ActiveState  string    `json:"active_state"`  // active, inactive, failed, ...
+	SubState     string    `json:"sub_state"`     // running, exited, dead, ...
+	EnabledState string    `json:"enabled_state"` // enabled, disabled, static, masked, ...
+	MainPID      uint32    `json:"main_pid"`      // services only, 0 when not running
+}
+
+// UnitFileChange is a symlink created or removed by enabling, disabling or
+// masking a unit.
+type UnitFileChange struct {
+	Type        string `json:"type"` // symlink or unlink
+	Filename    string `json:"filename"`
+	Destination string `json:"destination"`
+}
+
+// UnitActionResult confirms the outcome of an action on a unit.
+type UnitActionResult struct {
+	Unit      string           `json:"unit"`
+	Action    string           `json:"action"`
+	JobResult string           `json:"job_result,omitempty"` // done, failed, canceled, timeout, dependency, skipped
+	Changes   []UnitFileChange `json:"changes,omitempty"`
+	State     Unit             `json:"state"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// UnitFile is the content of a unit file or one of its drop-ins.
+type UnitFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// UnitDetail is the expanded view of a single unit.
+type UnitDetail struct {
+	Unit Unit `json:"unit"`
+	// Properties holds every D-Bus property of the unit, including the type
+	// specific ones such as ExecMainStatus or MemoryCurrent for services.
+	// Timestamps are microseconds since the epoch.
+	Properties map[string]any `json:"properties"`
+	// Dependencies maps dependency properties such as Requires, After or
+	// the reverse WantedBy to the units listed in them.
+	Dependencies map[string][]string `json:"dependencies"`
+	// Files is the unit file followed by its drop-ins in the order applied.
+	Files []UnitFile `json:"files"`
+}
+
+// Timer is a systemd timer unit and the last run of the unit it activates.
+// Times are omitted when systemd reports none.
+type Timer struct {
+	Name        string    `json:"name"`
+	Scope       UnitScope `json:"scope"`
+	Description string    `json:"description"`
+	ActiveState string    `json:"active_state"`
+	Unit        string    `json:"unit"` // unit activated by the timer, usually a service
+	// NextElapseRealtime is the next calendar (OnCalendar=) trigger.
+	NextElapseRealtime *time.Time `json:"next_elapse_realtime,omitempty"`
+	// NextElapseMonotonic is the next relative (OnBootSec=, OnUnitActiveSec=,
+	// ...) trigger converted to wall clock time.
+	NextElapseMonotonic *time.Time `json:"next_elapse_monotonic,omitempty"`
+	LastTrigger         *time.Time `json:"last_trigger,omitempty"`
+	// UnitResult is the result of the last run of Unit: success, exit-code,
+	// signal, timeout, ...
+	UnitResult      string `json:"unit_result"`
+	UnitActiveState string `json:"unit_active_state"`
+	UnitExitStatus  int32  `json:"unit_exit_status"`
+}